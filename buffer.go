@@ -20,6 +20,12 @@ type Transport interface {
 	Stop()
 }
 
+// Flusher is an optional interface of the Transport to synchronously write out all the
+// transactions it has been notified about, used to guarantee the delivery before Panic
+type Flusher interface {
+	Flush()
+}
+
 type buffer struct {
 	logger            *logger
 	stop              chan struct{}
@@ -32,6 +38,7 @@ type buffer struct {
 
 	transactionFlush chan bool
 	flushSent        bool
+	flushLock        sync.Mutex
 
 	backlog     *cache.Line
 	backlogLock sync.Mutex
@@ -61,11 +68,11 @@ func (b *buffer) start(outputs []Transport) {
 	b.outputs = outputs
 	b.refcount = len(outputs)
 
+	b.wg.Add(1)
 	go b.loop()
 }
 
 func (b *buffer) loop() {
-	b.wg.Add(1)
 	defer b.wg.Done()
 
 	tm := time.NewTimer(b.logger.configuration.TransactionTimeout)
@@ -118,7 +125,21 @@ func (b *buffer) shutdown() {
 	}
 }
 
+// sync flushes the current transaction and waits for the transports implementing Flusher to write it out
+func (b *buffer) sync() {
+	b.flush()
+
+	for _, t := range b.outputs {
+		if f, ok := t.(Flusher); ok {
+			f.Flush()
+		}
+	}
+}
+
 func (b *buffer) flush() {
+	b.flushLock.Lock()
+	defer b.flushLock.Unlock()
+
 	b.currentTransactionLock.Lock()
 	b.flushSent = false
 	if len(b.currentTransaction) == 0 {
@@ -0,0 +1,97 @@
+package loge
+
+import (
+	"path/filepath"
+	"runtime"
+	"strconv"
+)
+
+// number of stack frames between the user code and runtime.Caller:
+// annotate, Logger.output or BufferElement.submit and the public API function
+const callerFrames = 3
+
+// Caller is the source location of the log call
+type Caller struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Function string `json:"function,omitempty"`
+}
+
+// String returns the short file name and line number, as the standard log package does with log.Lshortfile
+func (c *Caller) String() string {
+	return filepath.Base(c.File) + ":" + strconv.Itoa(c.Line)
+}
+
+// CallerSkip returns a function to set the number of additional stack frames to skip when the caller is captured.
+// Useful when the log calls are made through the application's own wrapper functions.
+func CallerSkip(skip int) func(*configuration) *configuration {
+	return func(l *configuration) *configuration {
+		l.CallerSkip = skip
+		return l
+	}
+}
+
+// AddCallerSkip creates a child logger skipping the additional number of stack frames when the caller is captured
+func (lg *Logger) AddCallerSkip(skip int) *Logger {
+	child := lg.clone()
+	child.callerSkip += skip
+	return child
+}
+
+// annotate fills the source location and the stack trace of the entry,
+// it must be called directly from output or submit, see callerFrames
+func (l *logger) annotate(be *BufferElement, level uint32, skip int) {
+	skip += callerFrames + l.configuration.CallerSkip
+
+	if (l.configuration.Mode & outputIncludeLine) != 0 {
+		if pc, file, line, ok := runtime.Caller(skip); ok {
+			be.Caller = &Caller{
+				File: file,
+				Line: line,
+			}
+
+			if f := runtime.FuncForPC(pc); f != nil {
+				be.Caller.Function = f.Name()
+			}
+		}
+	}
+
+	if l.stackTraceEnabled(level) {
+		pcs := make([]uintptr, maxStackDepth)
+		pcs = pcs[:runtime.Callers(skip+1, pcs)] // runtime.Callers counts itself as well
+		l.fillStackTrace(be, pcs)
+	}
+}
+
+// annotateFromPC fills the source location and the stack trace of the entry made at the program counter
+func (l *logger) annotateFromPC(be *BufferElement, level uint32, pc uintptr) {
+	if pc == 0 {
+		return
+	}
+
+	if (l.configuration.Mode & outputIncludeLine) != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		if frame.File != "" {
+			be.Caller = &Caller{
+				File:     frame.File,
+				Line:     frame.Line,
+				Function: frame.Function,
+			}
+		}
+	}
+
+	if l.stackTraceEnabled(level) {
+		pcs := make([]uintptr, maxStackDepth)
+		pcs = pcs[:runtime.Callers(1, pcs)]
+
+		// drop the frames above the log call
+		for i, p := range pcs {
+			if p == pc {
+				pcs = pcs[i:]
+				break
+			}
+		}
+
+		l.fillStackTrace(be, pcs)
+	}
+}
@@ -0,0 +1,95 @@
+package loge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func currentLine() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
+
+func logThroughHelper(lg *Logger, message string) {
+	lg.AddCallerSkip(1).Info(message)
+}
+
+func TestCallerCapture(t *testing.T) {
+	var output bytes.Buffer
+
+	lg := New(
+		EnableOutputConsole(true),
+		EnableOutputConsoleInJSONFormat(true),
+		EnableOutputIncludeLine(true),
+		ConsoleOutput(&output),
+		LogLevels(LogLevelInfo),
+	)
+	defer lg.Shutdown()
+
+	saved := std
+	std = lg
+	defer func() { std = saved }()
+
+	var expected []int
+	expected = append(expected, currentLine()+1)
+	lg.Info("method")
+	expected = append(expected, currentLine()+1)
+	lg.With("uid", 42).Info("with")
+	expected = append(expected, currentLine()+1)
+	lg.WithFields(map[string]interface{}{"uid": 42}).Info("child")
+	expected = append(expected, currentLine()+1)
+	Info("package")
+	expected = append(expected, currentLine()+1)
+	InfoCtx(NewContext(context.Background(), map[string]interface{}{"uid": 42}), "context")
+	expected = append(expected, currentLine()+1)
+	slog.New(lg.SlogHandler()).Info("slog")
+	expected = append(expected, currentLine()+1)
+	logThroughHelper(lg, "helper")
+
+	dec := json.NewDecoder(&output)
+	for i := 0; dec.More(); i++ {
+		var entry struct {
+			Message string  `json:"msg"`
+			Caller  *Caller `json:"caller"`
+		}
+		if err := dec.Decode(&entry); err != nil {
+			t.Fatal(err)
+		}
+
+		if i >= len(expected) {
+			t.Fatalf("unexpected entry %q", entry.Message)
+		}
+
+		if entry.Caller == nil || filepath.Base(entry.Caller.File) != "caller_test.go" || entry.Caller.Line != expected[i] ||
+			!strings.HasSuffix(entry.Caller.Function, ".TestCallerCapture") {
+			t.Errorf("unexpected caller of %q: %+v, expected line %d", entry.Message, entry.Caller, expected[i])
+		}
+	}
+}
+
+func TestCallerText(t *testing.T) {
+	var output bytes.Buffer
+
+	lg := New(
+		EnableOutputConsole(true),
+		EnableOutputIncludeLine(true),
+		ConsoleOutput(&output),
+		LogLevels(LogLevelInfo),
+	)
+	defer lg.Shutdown()
+
+	line := currentLine() + 1
+	lg.Info("text")
+
+	expected := "caller_test.go:" + strconv.Itoa(line) + ": text\n"
+	if !strings.HasSuffix(output.String(), expected) {
+		t.Errorf("unexpected output %q", output.String())
+	}
+}
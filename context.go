@@ -0,0 +1,159 @@
+package loge
+
+import (
+	"context"
+	"fmt"
+)
+
+type contextKey struct{}
+
+// ContextExtractor returns a function to add a hook extracting fields from the context.
+// Extractors are called for every context-aware entry, they must be fast and safe for concurrent use.
+func ContextExtractor(extractor func(ctx context.Context) map[string]interface{}) func(*configuration) *configuration {
+	return func(l *configuration) *configuration {
+		l.contextExtractors = append(l.contextExtractors, extractor)
+		return l
+	}
+}
+
+// NewContext returns a copy of the context carrying the fields, fields already attached to the context are preserved
+func NewContext(ctx context.Context, fields map[string]interface{}) context.Context {
+	existing, _ := ctx.Value(contextKey{}).(map[string]interface{})
+
+	merged := make(map[string]interface{}, len(existing)+len(fields))
+	for k, v := range existing {
+		merged[k] = v
+	}
+
+	for k, v := range fields {
+		if k != "" && v != nil {
+			merged[k] = v
+		}
+	}
+
+	return context.WithValue(ctx, contextKey{}, merged)
+}
+
+// FromContext returns a child logger of the default logger carrying the fields attached to the context
+func FromContext(ctx context.Context) *Logger {
+	return std.WithContext(ctx)
+}
+
+// WithContext returns a child logger carrying the fields attached to the context.
+// Fields are merged in the following order: logger fields, fields returned by the
+// configured extractors, fields attached with NewContext.
+func (lg *Logger) WithContext(ctx context.Context) *Logger {
+	if ctx == nil {
+		return lg
+	}
+
+	var fields map[string]interface{}
+	for _, extractor := range lg.l.configuration.contextExtractors {
+		extracted := extractor(ctx)
+		if len(extracted) == 0 {
+			continue
+		}
+
+		if fields == nil {
+			fields = make(map[string]interface{})
+		}
+
+		for k, v := range extracted {
+			fields[k] = v
+		}
+	}
+
+	if attached, ok := ctx.Value(contextKey{}).(map[string]interface{}); ok {
+		if fields == nil {
+			fields = attached
+		} else {
+			for k, v := range attached {
+				fields[k] = v
+			}
+		}
+	}
+
+	child := lg
+	if len(fields) > 0 {
+		child = lg.WithFields(fields)
+	}
+
+	if s := scopeFromContext(ctx); s != nil {
+		if child == lg {
+			child = lg.clone()
+		}
+		child.scope = s
+	}
+
+	return child
+}
+
+// InfoCtx creates creates a new "info" log entry with the fields attached to the context
+func InfoCtx(ctx context.Context, format string, v ...interface{}) {
+	if std.enabledCtx(ctx, LogLevelInfo) {
+		std.WithContext(ctx).output(LogLevelInfo, format, fmt.Sprintf(format, v...))
+	}
+}
+
+// DebugCtx creates creates a new "debug" log entry with the fields attached to the context
+func DebugCtx(ctx context.Context, format string, v ...interface{}) {
+	if std.enabledCtx(ctx, LogLevelDebug) {
+		std.WithContext(ctx).output(LogLevelDebug, format, fmt.Sprintf(format, v...))
+	}
+}
+
+// TraceCtx creates creates a new "trace" log entry with the fields attached to the context
+func TraceCtx(ctx context.Context, format string, v ...interface{}) {
+	if std.enabledCtx(ctx, LogLevelTrace) {
+		std.WithContext(ctx).output(LogLevelTrace, format, fmt.Sprintf(format, v...))
+	}
+}
+
+// WarnCtx creates creates a new "warning" log entry with the fields attached to the context
+func WarnCtx(ctx context.Context, format string, v ...interface{}) {
+	if std.enabledCtx(ctx, LogLevelWarning) {
+		std.WithContext(ctx).output(LogLevelWarning, format, fmt.Sprintf(format, v...))
+	}
+}
+
+// ErrorCtx creates creates a new "error" log entry with the fields attached to the context
+func ErrorCtx(ctx context.Context, format string, v ...interface{}) {
+	if std.enabledCtx(ctx, LogLevelError) {
+		std.WithContext(ctx).output(LogLevelError, format, fmt.Sprintf(format, v...))
+	}
+}
+
+// InfoCtx creates creates a new "info" log entry with the fields attached to the context
+func (lg *Logger) InfoCtx(ctx context.Context, format string, v ...interface{}) {
+	if lg.enabledCtx(ctx, LogLevelInfo) {
+		lg.WithContext(ctx).output(LogLevelInfo, format, fmt.Sprintf(format, v...))
+	}
+}
+
+// DebugCtx creates creates a new "debug" log entry with the fields attached to the context
+func (lg *Logger) DebugCtx(ctx context.Context, format string, v ...interface{}) {
+	if lg.enabledCtx(ctx, LogLevelDebug) {
+		lg.WithContext(ctx).output(LogLevelDebug, format, fmt.Sprintf(format, v...))
+	}
+}
+
+// TraceCtx creates creates a new "trace" log entry with the fields attached to the context
+func (lg *Logger) TraceCtx(ctx context.Context, format string, v ...interface{}) {
+	if lg.enabledCtx(ctx, LogLevelTrace) {
+		lg.WithContext(ctx).output(LogLevelTrace, format, fmt.Sprintf(format, v...))
+	}
+}
+
+// WarnCtx creates creates a new "warning" log entry with the fields attached to the context
+func (lg *Logger) WarnCtx(ctx context.Context, format string, v ...interface{}) {
+	if lg.enabledCtx(ctx, LogLevelWarning) {
+		lg.WithContext(ctx).output(LogLevelWarning, format, fmt.Sprintf(format, v...))
+	}
+}
+
+// ErrorCtx creates creates a new "error" log entry with the fields attached to the context
+func (lg *Logger) ErrorCtx(ctx context.Context, format string, v ...interface{}) {
+	if lg.enabledCtx(ctx, LogLevelError) {
+		lg.WithContext(ctx).output(LogLevelError, format, fmt.Sprintf(format, v...))
+	}
+}
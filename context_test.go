@@ -0,0 +1,54 @@
+package loge
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+)
+
+type tenantKey struct{}
+
+func TestContextLogging(t *testing.T) {
+	var output bytes.Buffer
+
+	lg := New(
+		EnableOutputConsole(true),
+		EnableOutputConsoleInJSONFormat(true),
+		ConsoleOutput(&output),
+		LogLevels(LogLevelInfo|LogLevelError),
+		ContextExtractor(func(ctx context.Context) map[string]interface{} {
+			if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+				return map[string]interface{}{"tenant": tenant}
+			}
+			return nil
+		}),
+	)
+	defer lg.Shutdown()
+
+	ctx := NewContext(context.Background(), map[string]interface{}{"request_id": "r1"})
+	ctx = NewContext(ctx, map[string]interface{}{"user": "pap"})
+	ctx = context.WithValue(ctx, tenantKey{}, "acme")
+
+	lg.InfoCtx(ctx, "info %d", 1)
+	lg.DebugCtx(ctx, "not enabled")
+	lg.WithContext(ctx).With("status", 500).Error("failed")
+	lg.InfoCtx(context.Background(), "no fields")
+
+	entries := decodeEntries(t, &output)
+	if len(entries) != 3 {
+		t.Fatalf("unexpected number of entries %d", len(entries))
+	}
+
+	expected := []map[string]interface{}{
+		{"request_id": "r1", "user": "pap", "tenant": "acme"},
+		{"request_id": "r1", "user": "pap", "tenant": "acme", "status": float64(500)},
+		nil,
+	}
+
+	for i, entry := range entries {
+		if !reflect.DeepEqual(entry.Data, expected[i]) {
+			t.Errorf("unexpected data of entry %q: %v", entry.Message, entry.Data)
+		}
+	}
+}
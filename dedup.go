@@ -0,0 +1,128 @@
+package loge
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Deduplicate returns a function to collapse the consecutive duplicate entries.  Entries are duplicates if their
+// message, level and the values of the Data keys are equal.  Duplicates are counted instead of written, the count
+// is reported with a single "last message repeated N times" entry when a different entry is written or the window
+// since the first duplicate closes, 0 keeps the window open until a different entry arrives.
+func Deduplicate(window time.Duration, keys ...string) func(*configuration) *configuration {
+	return func(l *configuration) *configuration {
+		l.Deduplicate = true
+		l.DeduplicateWindow = window
+		l.DeduplicateKeys = keys
+		return l
+	}
+}
+
+type deduplicator struct {
+	logger *logger
+	window time.Duration
+	keys   []string
+
+	lock       sync.Mutex
+	last       *BufferElement // last delivered entry
+	repeated   int
+	firstTime  time.Time
+	lastTime   time.Time
+	timer      *time.Timer
+	generation uint64 // incremented when the repeated entries are reported, invalidates the pending timer
+}
+
+// newDeduplicator returns nil if the deduplication is disabled
+func newDeduplicator(l *logger) *deduplicator {
+	if !l.configuration.Deduplicate {
+		return nil
+	}
+
+	return &deduplicator{
+		logger: l,
+		window: l.configuration.DeduplicateWindow,
+		keys:   l.configuration.DeduplicateKeys,
+	}
+}
+
+// write delivers the entry unless it duplicates the last one, panic and fatal entries are always delivered
+func (d *deduplicator) write(be *BufferElement) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if be.Level != LogLevelPanic && be.Level != LogLevelFatal && d.duplicates(be) {
+		if d.repeated == 0 {
+			d.firstTime = be.Timestamp
+			if d.window > 0 {
+				generation := d.generation
+				d.timer = time.AfterFunc(d.window, func() {
+					d.expire(generation)
+				})
+			}
+		}
+
+		d.repeated++
+		d.lastTime = be.Timestamp
+		return
+	}
+
+	d.report()
+	d.last = be
+	d.logger.deliver(be)
+}
+
+func (d *deduplicator) duplicates(be *BufferElement) bool {
+	if d.last == nil || d.last.Level != be.Level || d.last.Message != be.Message {
+		return false
+	}
+
+	for _, key := range d.keys {
+		last, _ := d.last.Lookup(key)
+		current, _ := be.Lookup(key)
+		if !reflect.DeepEqual(last, current) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (d *deduplicator) expire(generation uint64) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if generation == d.generation {
+		d.report()
+	}
+}
+
+// flush reports the pending repeated entries
+func (d *deduplicator) flush() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.report()
+}
+
+// report must be called with the lock held
+func (d *deduplicator) report() {
+	if d.repeated == 0 {
+		return
+	}
+
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+
+	be := d.logger.newEntry(fmt.Sprintf("last message repeated %d times", d.repeated), d.last.Level)
+	be.With("repeated", d.repeated)
+	be.With("first_timestamp", d.firstTime.Format(time.RFC3339Nano))
+	be.With("last_timestamp", d.lastTime.Format(time.RFC3339Nano))
+	d.logger.deliver(be)
+
+	d.repeated = 0
+	d.generation++
+}
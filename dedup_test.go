@@ -0,0 +1,80 @@
+package loge
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeduplicate(t *testing.T) {
+	var output bytes.Buffer
+
+	lg := New(
+		EnableOutputConsole(true),
+		EnableOutputConsoleInJSONFormat(true),
+		ConsoleOutput(&output),
+		LogLevels(LogLevelInfo|LogLevelError),
+		Deduplicate(0, "host"),
+	)
+
+	for i := 0; i < 4; i++ {
+		lg.With("host", "a").With("attempt", i).Error("connection refused")
+	}
+	lg.With("host", "b").Error("connection refused")
+	lg.Info("connection refused")
+	lg.Info("connection refused")
+	lg.Shutdown()
+
+	entries := decodeEntries(t, &output)
+	expected := []struct{ message, level string }{
+		{"connection refused", "error"},
+		{"last message repeated 3 times", "error"},
+		{"connection refused", "error"},
+		{"connection refused", "info"},
+		{"last message repeated 1 times", "info"},
+	}
+
+	if len(entries) != len(expected) {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+
+	for i, e := range expected {
+		if entries[i].Message != e.message || entries[i].Level != e.level {
+			t.Errorf("unexpected entry %d %+v", i, entries[i])
+		}
+	}
+
+	summary := entries[1]
+	first, err1 := time.Parse(time.RFC3339Nano, summary.Data["first_timestamp"].(string))
+	last, err2 := time.Parse(time.RFC3339Nano, summary.Data["last_timestamp"].(string))
+	if summary.Data["repeated"] != float64(3) || err1 != nil || err2 != nil || last.Before(first) {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+}
+
+func TestDeduplicateWindow(t *testing.T) {
+	var output bytes.Buffer
+
+	lg := New(
+		EnableOutputConsole(true),
+		ConsoleOutput(&output),
+		LogLevels(LogLevelWarning),
+		Deduplicate(10*time.Millisecond),
+	)
+
+	lg.Warn("disk is full")
+	lg.Warn("disk is full")
+	lg.Warn("disk is full")
+	time.Sleep(50 * time.Millisecond)
+	lg.Warn("disk is full")
+	lg.Flush()
+
+	text := output.String()
+	lg.Shutdown()
+
+	if strings.Count(text, "disk is full") != 1 || strings.Count(text, "last message repeated 2 times") != 1 ||
+		strings.Count(text, "last message repeated 1 times") != 1 {
+		t.Errorf("unexpected output %q", text)
+	}
+}
@@ -0,0 +1,295 @@
+package loge
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	devBadgeWidth    = 7  // level names up to "warning" are padded
+	devCallerWidth   = 20 // caller column, longer locations shift the message
+	devFieldIndent   = "    "
+	devTimeFormat    = "15:04:05.000"
+	devRelativeWidth = 11 // "+12345.678s"
+
+	colorReset = "\x1b[0m"
+	colorDim   = "\x1b[2m"
+	colorKey   = "\x1b[36m"
+	colorError = "\x1b[31m"
+)
+
+// processStart is the reference of the relative timestamps if DevEncoder.Start is not set
+var processStart = time.Now()
+
+// DevEncoder is the human friendly console format for the development: time, level badge, caller and message in
+// aligned columns followed by the With() fields and the typed fields, one per line.  Maps, slices and structs are
+// written as indented JSON.
+type DevEncoder struct {
+	Color        bool      // ANSI colors, see NewDevEncoder
+	RelativeTime bool      // write the time elapsed since Start instead of the local time
+	Start        time.Time // reference of the relative timestamps (default is the program start)
+	Escaping     uint32    // escaping policy of the message, the field keys, the text values and the error chain
+}
+
+// NewDevEncoder creates the development encoder for the writer, colors are enabled if the writer is a terminal
+// and the NO_COLOR environment variable is not set.  Continuation lines of the messages are indented.
+func NewDevEncoder(w io.Writer) *DevEncoder {
+	return &DevEncoder{
+		Color:    colorSupported(w),
+		Escaping: EscapeIndent,
+	}
+}
+
+// Development returns a function to configure the logger for the local development: the console output in the
+// development format, debug and more severe levels and the source location of the entries
+func Development() func(*configuration) *configuration {
+	return func(l *configuration) *configuration {
+		l.Mode |= outputConsole | outputIncludeLine
+		l.MinLevel = LogLevelDebug
+		l.development = true
+		return l
+	}
+}
+
+// colorSupported reports if the writer is a terminal and the colors are not disabled with NO_COLOR (https://no-color.org)
+func colorSupported(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	fi, err := f.Stat()
+	return err == nil && (fi.Mode()&os.ModeCharDevice) != 0
+}
+
+// Encode /Encoder
+func (e DevEncoder) Encode(buf []byte, be *BufferElement) ([]byte, error) {
+	buf = e.appendTime(buf, be.Timestamp)
+	buf = append(buf, ' ')
+	buf = e.appendBadge(buf, be)
+	buf = append(buf, ' ')
+
+	if be.Caller != nil {
+		caller := be.Caller.String()
+		buf = e.colored(buf, colorDim, caller)
+		buf = appendPadding(buf, devCallerWidth-len(caller))
+		buf = append(buf, ' ')
+	}
+
+	buf = appendEscaped(buf, be.Message, e.Escaping)
+	buf = append(buf, '\n')
+	buf = e.appendFields(buf, be)
+
+	if len(be.ErrorChain) > 0 && e.Color {
+		buf = append(buf, colorError...)
+		buf = appendErrorChainText(buf, be.ErrorChain, e.Escaping)
+		buf = append(buf, colorReset...)
+	} else {
+		buf = appendErrorChainText(buf, be.ErrorChain, e.Escaping)
+	}
+
+	return appendStackFrames(buf, be.Stack), nil
+}
+
+func (e DevEncoder) appendTime(buf []byte, t time.Time) []byte {
+	if !e.RelativeTime {
+		return e.colored(buf, colorDim, t.Local().Format(devTimeFormat))
+	}
+
+	start := e.Start
+	if start.IsZero() {
+		start = processStart
+	}
+
+	elapsed := strconv.AppendFloat([]byte{'+'}, t.Sub(start).Seconds(), 'f', 3, 64)
+	elapsed = append(elapsed, 's')
+	buf = appendPadding(buf, devRelativeWidth-len(elapsed))
+	return e.colored(buf, colorDim, string(elapsed))
+}
+
+// appendBadge appends the upper case level name colored by the level severity, plain entries get an empty badge
+func (e DevEncoder) appendBadge(buf []byte, be *BufferElement) []byte {
+	name := strings.ToUpper(be.Levelstring)
+	if name == "" {
+		return appendPadding(buf, devBadgeWidth)
+	}
+
+	if e.Color {
+		buf = append(buf, badgeColor(be.Level)...)
+		buf = append(buf, name...)
+		buf = append(buf, colorReset...)
+	} else {
+		buf = append(buf, name...)
+	}
+
+	return appendPadding(buf, devBadgeWidth-len(name))
+}
+
+// badgeColor selects the color of a custom level by its severity the same way as of the built-in ones
+func badgeColor(level uint32) string {
+	switch severity := levelSeverity(level); {
+	case severity >= severityPanic:
+		return "\x1b[1;97;45m" // white on magenta
+	case severity >= severityError:
+		return "\x1b[1;97;41m" // white on red
+	case severity >= severityWarning:
+		return "\x1b[1;30;43m" // black on yellow
+	case severity >= severityInfo:
+		return "\x1b[1;30;42m" // black on green
+	case severity >= severityDebug:
+		return "\x1b[1;97;44m" // white on blue
+	}
+	return "\x1b[1;97;100m" // white on gray
+}
+
+// appendFields appends the With() fields in the sorted order followed by the typed fields, one per line with
+// the aligned values
+func (e DevEncoder) appendFields(buf []byte, be *BufferElement) []byte {
+	if len(be.Data) == 0 && len(be.Fields) == 0 {
+		return buf
+	}
+
+	keys := make([]string, 0, len(be.Data))
+	for key := range be.Data {
+		if !be.hasField(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	width := 0
+	for _, key := range keys {
+		if n := len(e.escapeKey(key)); n > width {
+			width = n
+		}
+	}
+	for _, f := range be.Fields {
+		if n := len(e.escapeKey(f.Key)); n > width {
+			width = n
+		}
+	}
+
+	for _, key := range keys {
+		buf = e.appendField(buf, key, width, be.Data[key])
+	}
+
+	for _, f := range be.Fields {
+		switch f.kind {
+		case fieldAny:
+			buf = e.appendField(buf, f.Key, width, f.iface)
+		case fieldObject:
+			buf = e.appendField(buf, f.Key, width, objectMap(f.iface.(ObjectMarshaler), 0))
+		case fieldString:
+			buf = e.appendField(buf, f.Key, width, f.str)
+		default:
+			buf = e.appendKey(buf, f.Key, width)
+			start := len(buf)
+			buf = append(escapeTail(f.appendText(buf), start, e.Escaping), '\n')
+		}
+	}
+
+	return buf
+}
+
+func (e DevEncoder) appendKey(buf []byte, key string, width int) []byte {
+	key = e.escapeKey(key)
+	buf = append(buf, devFieldIndent...)
+	buf = e.colored(buf, colorKey, key)
+	buf = appendPadding(buf, width-len(key))
+	return append(buf, ": "...)
+}
+
+// escapeKey escapes the control characters of the key, line breaks are escaped with any policy but EscapeNone
+func (e DevEncoder) escapeKey(key string) string {
+	if e.Escaping == EscapeNone || !needsEscaping(key) {
+		return key
+	}
+	return string(appendEscaped(nil, key, EscapeControl))
+}
+
+// appendField appends maps, slices and structs as indented JSON and the others as escaped text
+func (e DevEncoder) appendField(buf []byte, key string, width int, v interface{}) []byte {
+	buf = e.appendKey(buf, key, width)
+
+	if m, ok := v.(ObjectMarshaler); ok {
+		v = objectMap(m, 0)
+	}
+
+	switch x := v.(type) {
+	case string:
+		buf = appendEscaped(buf, x, e.Escaping)
+	case nil:
+		buf = append(buf, "<nil>"...)
+	default:
+		if prettyPrinted(v) {
+			var indented bytes.Buffer
+			raw := marshalJSON(v)
+			if json.Indent(&indented, raw, strings.Repeat(" ", len(devFieldIndent)+width+2), "  ") == nil {
+				buf = append(buf, indented.Bytes()...)
+			} else {
+				buf = append(buf, raw...)
+			}
+		} else {
+			start := len(buf)
+			buf = escapeTail(appendTextValue(buf, v), start, e.Escaping)
+		}
+	}
+
+	return append(buf, '\n')
+}
+
+// prettyPrinted reports if the value is written as JSON: maps, slices and structs without their own text form
+func prettyPrinted(v interface{}) bool {
+	switch v.(type) {
+	case error, []byte:
+		return false
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array:
+		return true
+	case reflect.Struct:
+		t := rv.Type()
+		for _, marshaler := range []reflect.Type{jsonMarshalerType, textMarshalerType, stringerType} {
+			if t.Implements(marshaler) || reflect.PtrTo(t).Implements(marshaler) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+func (e DevEncoder) colored(buf []byte, color string, s string) []byte {
+	if !e.Color {
+		return append(buf, s...)
+	}
+
+	buf = append(buf, color...)
+	buf = append(buf, s...)
+	return append(buf, colorReset...)
+}
+
+func appendPadding(buf []byte, n int) []byte {
+	for ; n > 0; n-- {
+		buf = append(buf, ' ')
+	}
+	return buf
+}
@@ -0,0 +1,111 @@
+package loge
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDevEncoder(t *testing.T) {
+	be := testElement(LogLevelWarning, "slow\nquery", map[string]interface{}{
+		"host":  "db1",
+		"query": map[string]interface{}{"table": "users"},
+	})
+	be.Caller = &Caller{File: "/src/db/conn.go", Line: 42}
+	be.Typed(Int("retries", 3))
+
+	encoder := DevEncoder{RelativeTime: true, Start: be.Timestamp.Add(-1500 * time.Millisecond), Escaping: EscapeIndent}
+	out, err := encoder.Encode(nil, be)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "    +1.500s WARNING conn.go:42           slow\n" +
+		"\t| query\n" +
+		"    host   : db1\n" +
+		"    query  : {\n" +
+		"               \"table\": \"users\"\n" +
+		"             }\n" +
+		"    retries: 3\n"
+	if string(out) != expected {
+		t.Errorf("unexpected output\n%s", out)
+	}
+
+	plain := testElement(0, "plain", nil)
+	out, _ = encoder.Encode(nil, plain)
+	if strings.Index(string(out), "plain") != devRelativeWidth+1+devBadgeWidth+1 {
+		t.Errorf("columns of the plain entry are not aligned: %q", out)
+	}
+}
+
+func TestDevEncoderColor(t *testing.T) {
+	be := testElement(LogLevelError, "failed", nil)
+
+	out, _ := DevEncoder{Color: true}.Encode(nil, be)
+	if !strings.Contains(string(out), badgeColor(LogLevelError)+"ERROR"+colorReset) {
+		t.Errorf("badge is not colored: %q", out)
+	}
+
+	out, _ = DevEncoder{}.Encode(nil, be)
+	if strings.Contains(string(out), "\x1b[") {
+		t.Errorf("unexpected colors: %q", out)
+	}
+}
+
+func TestColorSupported(t *testing.T) {
+	if colorSupported(&bytes.Buffer{}) {
+		t.Errorf("buffer is not a terminal")
+	}
+
+	if tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0); err == nil {
+		defer tty.Close()
+
+		t.Setenv("NO_COLOR", "1")
+		if colorSupported(tty) {
+			t.Errorf("NO_COLOR is ignored")
+		}
+	}
+}
+
+func TestDevelopment(t *testing.T) {
+	var output bytes.Buffer
+
+	lg := New(Development(), ConsoleOutput(&output))
+	lg.Debug("debug")
+	lg.Shutdown()
+
+	encoder, ok := lg.l.configuration.ConsoleEncoder.(*DevEncoder)
+	if !ok || encoder.Color {
+		t.Fatalf("unexpected console encoder %#v", lg.l.configuration.ConsoleEncoder)
+	}
+
+	if !strings.Contains(output.String(), " DEBUG   dev_test.go:") || !strings.HasSuffix(output.String(), " debug\n") {
+		t.Errorf("unexpected output %q", output.String())
+	}
+}
+
+func TestDevEncoderEscaping(t *testing.T) {
+	be := testElement(LogLevelError, "failed", map[string]interface{}{
+		"e":         errors.New("a\nFAKE LINE \x1b[31m"),
+		"key\nFAKE": "value",
+	})
+	be.Typed(Err(errors.New("b\nFAKE2")))
+
+	out, _ := DevEncoder{Escaping: EscapeControl}.Encode(nil, be)
+	for _, expected := range []string{
+		`    e        : a\nFAKE LINE \x1b[31m` + "\n",
+		`    key\nFAKE: value` + "\n",
+		`    error    : b\nFAKE2` + "\n",
+	} {
+		if !strings.Contains(string(out), expected) {
+			t.Errorf("%q is not found in the output\n%s", expected, out)
+		}
+	}
+
+	if strings.Count(string(out), "\n") != 4 || strings.Contains(string(out), "\x1b") {
+		t.Errorf("control characters are not escaped\n%s", out)
+	}
+}
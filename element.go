@@ -1,8 +1,8 @@
 package loge
 
 import (
-	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 )
 
@@ -13,21 +13,34 @@ type BufferElement struct {
 	Message     string                     `json:"msg"`
 	Level       uint32                     `json:"-"`
 	Levelstring string                     `json:"level,omitempty"`
+	Caller      *Caller                    `json:"caller,omitempty"`
+	Stack       []StackFrame               `json:"stack,omitempty"`
+	ErrorChain  []string                   `json:"error_chain,omitempty"`
 	Data        map[string]interface{}     `json:"data,omitempty"`
-
-	l *logger
+	Fields      []Field                    `json:"-"` // typed fields, see Typed
+
+	l          *logger
+	callerSkip int
+	component  string
+	template   string // format of the message, empty if the message is not formatted
+	scope      *scope
+	err        error
 }
 
-func inPlaceBufferElement(l *logger) *BufferElement {
+func inPlaceBufferElement(l *logger, fields map[string]interface{}) *BufferElement {
 	be := &BufferElement{
-		l:    l,
-		Data: make(map[string]interface{}),
+		l: l,
 	}
 
-	if len(l.configuration.defaultData) > 0 {
+	if len(l.configuration.defaultData)+len(fields) > 0 {
+		be.Data = make(map[string]interface{}, len(l.configuration.defaultData)+len(fields))
 		for k, v := range l.configuration.defaultData {
 			be.Data[k] = v
 		}
+
+		for k, v := range fields {
+			be.Data[k] = v
+		}
 	}
 
 	return be
@@ -45,21 +58,48 @@ func (be *BufferElement) fill(t time.Time, buf []byte, msg []byte, level uint32)
 	}
 }
 
-func (be *BufferElement) serializeData() string {
-	var serializedData string
-	for key, arg := range be.Data {
-		if len(serializedData) > 0 {
-			serializedData += ", "
+// appendData appends the With() parameters in the sorted order followed by the typed fields as <key: value, ...>
+func (be *BufferElement) appendData(buf []byte) []byte {
+	if len(be.Data) == 0 && len(be.Fields) == 0 {
+		return buf
+	}
+
+	buf = append(buf, '<')
+	first := true
+
+	if len(be.Data) > 0 {
+		keys := make([]string, 0, len(be.Data))
+		for key := range be.Data {
+			if !be.hasField(key) {
+				keys = append(keys, key)
+			}
 		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if !first {
+				buf = append(buf, ", "...)
+			}
+			first = false
 
-		serializedData += fmt.Sprintf("%s: %v", key, arg)
+			buf = append(buf, key...)
+			buf = append(buf, ": "...)
+			buf = appendTextValue(buf, be.Data[key])
+		}
 	}
 
-	if serializedData != "" {
-		serializedData = "<" + serializedData + "> "
+	for _, f := range be.Fields {
+		if !first {
+			buf = append(buf, ", "...)
+		}
+		first = false
+
+		buf = append(buf, f.Key...)
+		buf = append(buf, ": "...)
+		buf = f.appendText(buf)
 	}
 
-	return serializedData
+	return append(buf, "> "...)
 }
 
 // NewBufferElement creates a new log entry
@@ -69,21 +109,22 @@ func NewBufferElement(t time.Time, buf []byte, msg []byte, level uint32) *Buffer
 	return b
 }
 
-// Marshal marshals the record into json format
+// Marshal marshals the record into json format the same way as JSONEncoder, unserializable Data values are replaced
+// with placeholders and ObjectMarshaler values are written with MarshalLogObject
 func (be *BufferElement) Marshal() ([]byte, error) {
-	return json.Marshal(be)
-}
-
-// Size returns the record size in bytes
-func (be *BufferElement) Size() int {
-	// we do not count optional data fields in overall size
-	// for simplicity and speed
-	return dateTimeStringLength + len(be.Message)
+	buf, err := JSONEncoder{}.Encode(nil, be)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:len(buf)-1], nil
 }
 
 // With extends the log entry with optional parameters
 func (be *BufferElement) With(key string, value interface{}) *BufferElement {
 	if key != "" && value != nil {
+		if be.Data == nil {
+			be.Data = make(map[string]interface{})
+		}
 		be.Data[key] = value
 	}
 	return be
@@ -92,48 +133,66 @@ func (be *BufferElement) With(key string, value interface{}) *BufferElement {
 // Printf creates creates a new log entry
 func (be *BufferElement) Printf(format string, v ...interface{}) {
 	if be.l != nil {
-		be.l.submit(be, fmt.Sprintf(format, v...), 0)
+		be.submit(format, fmt.Sprintf(format, v...), 0)
 	}
 }
 
 // Println creates creates a new log entry
 func (be *BufferElement) Println(v ...interface{}) {
 	if be.l != nil {
-		be.l.submit(be, fmt.Sprintln(v...), 0)
+		be.submit("", fmt.Sprintln(v...), 0)
 	}
 }
 
 // Info creates creates a new "info" log entry
 func (be *BufferElement) Info(format string, v ...interface{}) {
-	if (be.l != nil) && ((be.l.configuration.LogLevels & LogLevelInfo) != 0) {
-		be.l.submit(be, fmt.Sprintf(format, v...), LogLevelInfo)
+	if (be.l != nil) && be.enabled(LogLevelInfo) {
+		be.submit(format, fmt.Sprintf(format, v...), LogLevelInfo)
 	}
 }
 
 // Debug creates creates a new "debug" log entry
 func (be *BufferElement) Debug(format string, v ...interface{}) {
-	if (be.l != nil) && ((be.l.configuration.LogLevels & LogLevelDebug) != 0) {
-		be.l.submit(be, fmt.Sprintf(format, v...), LogLevelDebug)
+	if (be.l != nil) && be.enabled(LogLevelDebug) {
+		be.submit(format, fmt.Sprintf(format, v...), LogLevelDebug)
 	}
 }
 
 // Trace creates creates a new "trace" log entry
 func (be *BufferElement) Trace(format string, v ...interface{}) {
-	if (be.l != nil) && ((be.l.configuration.LogLevels & LogLevelTrace) != 0) {
-		be.l.submit(be, fmt.Sprintf(format, v...), LogLevelTrace)
+	if (be.l != nil) && be.enabled(LogLevelTrace) {
+		be.submit(format, fmt.Sprintf(format, v...), LogLevelTrace)
 	}
 }
 
 // Warn creates creates a new "warning" log entry
 func (be *BufferElement) Warn(format string, v ...interface{}) {
-	if (be.l != nil) && ((be.l.configuration.LogLevels & LogLevelWarning) != 0) {
-		be.l.submit(be, fmt.Sprintf(format, v...), LogLevelWarning)
+	if (be.l != nil) && be.enabled(LogLevelWarning) {
+		be.submit(format, fmt.Sprintf(format, v...), LogLevelWarning)
 	}
 }
 
 // Error creates creates a new "error" log entry
 func (be *BufferElement) Error(format string, v ...interface{}) {
-	if (be.l != nil) && ((be.l.configuration.LogLevels & LogLevelError) != 0) {
-		be.l.submit(be, fmt.Sprintf(format, v...), LogLevelError)
+	if (be.l != nil) && be.enabled(LogLevelError) {
+		be.submit(format, fmt.Sprintf(format, v...), LogLevelError)
 	}
 }
+
+// Log creates a new log entry of the given level, including custom levels registered with RegisterLevel
+func (be *BufferElement) Log(level uint32, format string, v ...interface{}) {
+	if (be.l != nil) && be.enabled(level) {
+		be.submit(format, fmt.Sprintf(format, v...), level)
+	}
+}
+
+func (be *BufferElement) enabled(level uint32) bool {
+	return be.l.enabledFor(be.component, level) || be.scope.active()
+}
+
+// submit must be called directly from the public API functions, see callerFrames
+func (be *BufferElement) submit(template string, message string, level uint32) {
+	be.template = template
+	be.l.annotate(be, level, be.callerSkip)
+	be.l.submit(be, message, level)
+}
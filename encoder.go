@@ -0,0 +1,100 @@
+package loge
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Encoder serializes log entries for the console output and transports
+type Encoder interface {
+	// Encode appends the serialized entry followed by the line break to buf and returns the extended buffer
+	Encode(buf []byte, be *BufferElement) ([]byte, error)
+}
+
+// TextEncoder is the plain text format: local timestamp followed by the message
+type TextEncoder struct {
+	OptionalData bool   // include optional With() fields before the message
+	Escaping     uint32 // escaping policy of the message, the data and the error chain (default EscapeNone)
+}
+
+// Encode /Encoder
+func (e TextEncoder) Encode(buf []byte, be *BufferElement) ([]byte, error) {
+	buf = append(buf, be.Timestring[:]...)
+	if be.Caller != nil {
+		buf = append(buf, be.Caller.String()...)
+		buf = append(buf, ": "...)
+	}
+	if e.OptionalData {
+		start := len(buf)
+		buf = escapeTail(be.appendData(buf), start, e.Escaping)
+	}
+	buf = appendEscaped(buf, be.Message, e.Escaping)
+	buf = append(buf, '\n')
+	return appendStackText(buf, be, e.Escaping), nil
+}
+
+// TemplateEntry is the data passed to the TemplateEncoder template
+type TemplateEntry struct {
+	Time       time.Time              // entry time in UTC
+	Timestring string                 // formatted local time, as used by the text format
+	Level      string                 // level name, empty for Printf and standard log entries
+	Caller     *Caller                // source location, nil if not captured
+	Stack      []StackFrame           // captured stack trace
+	ErrorChain []string               // unwrapped chain of the attached error
+	Message    string                 // log message
+	Data       map[string]interface{} // optional fields, including the typed ones
+}
+
+// TemplateEncoder formats the entries with a text/template.
+// Templates receive a TemplateEntry and can use "json", "upper" and "escape" functions,
+// "escape" writes the control characters of a string as escape sequences, see EscapeControl.
+type TemplateEncoder struct {
+	tmpl *template.Template
+}
+
+// NewTemplateEncoder parses the template text and creates a new encoder, a line break
+// is added after each entry if the template output does not end with one
+func NewTemplateEncoder(text string) (*TemplateEncoder, error) {
+	tmpl, err := template.New("loge").Funcs(template.FuncMap{
+		"upper": strings.ToUpper,
+		"escape": func(s string) string {
+			return string(appendEscaped(nil, s, EscapeControl))
+		},
+		"json": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TemplateEncoder{tmpl: tmpl}, nil
+}
+
+// Encode /Encoder
+func (e *TemplateEncoder) Encode(buf []byte, be *BufferElement) ([]byte, error) {
+	var out bytes.Buffer
+	err := e.tmpl.Execute(&out, &TemplateEntry{
+		Time:       be.Timestamp,
+		Timestring: strings.TrimSuffix(string(be.Timestring[:]), " "),
+		Level:      be.Levelstring,
+		Caller:     be.Caller,
+		Stack:      be.Stack,
+		ErrorChain: be.ErrorChain,
+		Message:    be.Message,
+		Data:       be.mergedData(),
+	})
+	if err != nil {
+		return buf, err
+	}
+
+	buf = append(buf, out.Bytes()...)
+	if out.Len() == 0 || buf[len(buf)-1] != '\n' {
+		buf = append(buf, '\n')
+	}
+	return buf, nil
+}
@@ -0,0 +1,75 @@
+package loge
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testElement(level uint32, message string, data map[string]interface{}) *BufferElement {
+	t := time.Date(2020, 5, 17, 10, 20, 30, 123456000, time.UTC)
+
+	var timestamp []byte
+	dumpTimeToBuffer(&timestamp, t)
+
+	be := NewBufferElement(t, timestamp, []byte(message), level)
+	be.Data = data
+	return be
+}
+
+func TestEncoders(t *testing.T) {
+	be := testElement(LogLevelInfo, "hello world", map[string]interface{}{"uid": 42})
+
+	tmpl, err := NewTemplateEncoder(`{{upper .Level}} {{.Message}} {{json .Data}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		encoder  Encoder
+		expected string
+	}{
+		{"text", TextEncoder{}, "2020/05/17 10:20:30.123456 hello world\n"},
+		{"text with data", TextEncoder{OptionalData: true}, "2020/05/17 10:20:30.123456 <uid: 42> hello world\n"},
+		{"json", JSONEncoder{}, `{"time":"2020-05-17T10:20:30.123456Z","msg":"hello world","level":"info","data":{"uid":42}}` + "\n"},
+		{"logfmt", LogfmtEncoder{}, `ts=2020-05-17T10:20:30.123456Z level=info msg="hello world" uid=42` + "\n"},
+		{"template", tmpl, `INFO hello world {"uid":42}` + "\n"},
+	}
+
+	for _, test := range tests {
+		out, err := test.encoder.Encode(nil, be)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		} else if string(out) != test.expected {
+			t.Errorf("%s: unexpected output %q", test.name, out)
+		}
+	}
+}
+
+func TestWriterTransport(t *testing.T) {
+	var console, custom bytes.Buffer
+
+	lg := New(
+		EnableOutputConsole(true),
+		ConsoleOutput(&console),
+		ConsoleEncoder(LogfmtEncoder{}),
+		LogLevels(LogLevelInfo),
+		Transports(func(list TransactionList) []Transport {
+			return []Transport{NewWriterTransport(list, &custom, JSONEncoder{})}
+		}),
+	)
+
+	lg.With("uid", 42).Info("entry")
+	lg.Shutdown()
+
+	if !strings.HasPrefix(console.String(), "ts=") || !strings.HasSuffix(console.String(), "msg=entry uid=42\n") {
+		t.Errorf("unexpected console output %q", console.String())
+	}
+
+	entries := decodeEntries(t, &custom)
+	if len(entries) != 1 || entries[0].Message != "entry" || entries[0].Data["uid"] != float64(42) {
+		t.Errorf("unexpected transport output %+v", entries)
+	}
+}
@@ -0,0 +1,99 @@
+package loge
+
+import (
+	"unicode/utf8"
+)
+
+// Escaping policies of the text output
+const (
+	EscapeNone    uint32 = 0 // write the message and the data as is
+	EscapeControl uint32 = 1 // write the line breaks and the other control characters as \n, \r, \t, \x1b and \u0085 escapes
+	EscapeIndent  uint32 = 2 // start the continuation lines with the indentation marker, escape the other control characters
+)
+
+// continuationMarker starts each continuation line of a multi-line message with the EscapeIndent policy
+const continuationMarker = "\n\t| "
+
+// ConsoleEscaping returns a function to set the escaping policy of the default text console output
+func ConsoleEscaping(mode uint32) func(*configuration) *configuration {
+	return func(l *configuration) *configuration {
+		l.ConsoleEscaping = mode
+		return l
+	}
+}
+
+// FileEscaping returns a function to set the escaping policy of the default text file output
+func FileEscaping(mode uint32) func(*configuration) *configuration {
+	return func(l *configuration) *configuration {
+		l.FileEscaping = mode
+		return l
+	}
+}
+
+// needsEscaping reports if the text contains control characters or invalid UTF-8
+func needsEscaping(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < ' ' || c >= 0x7f {
+			return true
+		}
+	}
+	return false
+}
+
+// appendEscaped appends the text escaped according to the policy
+func appendEscaped(buf []byte, s string, mode uint32) []byte {
+	if mode == EscapeNone || !needsEscaping(s) {
+		return append(buf, s...)
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= ' ' && c < 0x7f {
+			buf = append(buf, c)
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			buf = append(buf, '\\', 'x', hexDigits[c>>4], hexDigits[c&0xf])
+		case mode == EscapeIndent && (r == '\n' || r == '\r'):
+			if r == '\r' && i+1 < len(s) && s[i+1] == '\n' {
+				size++ // CRLF is a single line break
+			}
+			buf = append(buf, continuationMarker...)
+		case r == '\n':
+			buf = append(buf, '\\', 'n')
+		case r == '\r':
+			buf = append(buf, '\\', 'r')
+		case r == '\t':
+			buf = append(buf, '\\', 't')
+		case r < ' ' || r == 0x7f:
+			buf = append(buf, '\\', 'x', hexDigits[r>>4], hexDigits[r&0xf])
+		case r >= 0x80 && r <= 0x9f:
+			buf = append(buf, '\\', 'u', '0', '0', hexDigits[r>>4], hexDigits[r&0xf])
+		default:
+			buf = append(buf, s[i:i+size]...)
+		}
+
+		i += size
+	}
+
+	return buf
+}
+
+// escapeTail escapes the text appended to buf after the start offset
+func escapeTail(buf []byte, start int, mode uint32) []byte {
+	if mode == EscapeNone {
+		return buf
+	}
+
+	for _, c := range buf[start:] {
+		if c < ' ' || c >= 0x7f {
+			return appendEscaped(buf[:start], string(buf[start:]), mode)
+		}
+	}
+
+	return buf
+}
@@ -0,0 +1,74 @@
+package loge
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEscaping(t *testing.T) {
+	message := "login failed\n2020/05/17 10:20:31.000000 admin logged in\r\x1b[31mred\u009b\xff é"
+
+	tests := []struct {
+		mode     uint32
+		expected string
+	}{
+		{EscapeNone, message},
+		{EscapeControl, `login failed\n2020/05/17 10:20:31.000000 admin logged in\r\x1b[31mred\u009b\xff é`},
+		{EscapeIndent, "login failed\n\t| 2020/05/17 10:20:31.000000 admin logged in\n\t| \\x1b[31mred\\u009b\\xff é"},
+	}
+
+	for _, test := range tests {
+		if escaped := string(appendEscaped(nil, message, test.mode)); escaped != test.expected {
+			t.Errorf("mode %d: unexpected output %q", test.mode, escaped)
+		}
+	}
+
+	if escaped := string(appendEscaped(nil, "a\r\nb", EscapeIndent)); escaped != "a\n\t| b" {
+		t.Errorf("CRLF is not a single line break: %q", escaped)
+	}
+}
+
+func TestTextEncoderEscaping(t *testing.T) {
+	be := testElement(LogLevelInfo, "first\nsecond", map[string]interface{}{"user": "bob\nadmin"})
+	be.ErrorChain = []string{"wrapped: bad\ninput", "bad\ninput"}
+
+	out, _ := TextEncoder{OptionalData: true, Escaping: EscapeControl}.Encode(nil, be)
+	expected := "2020/05/17 10:20:30.123456 <user: bob\\nadmin> first\\nsecond\n" +
+		"\terror: wrapped: bad\\ninput\n" +
+		"\tcaused by: bad\\ninput\n"
+	if string(out) != expected {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	out, _ = JSONEncoder{}.Encode(nil, be)
+	if !strings.Contains(string(out), `"msg":"first\nsecond"`) {
+		t.Errorf("JSON message is not raw: %s", out)
+	}
+}
+
+func TestOutputEscaping(t *testing.T) {
+	var console bytes.Buffer
+
+	lg := New(
+		EnableOutputConsole(true),
+		ConsoleOutput(&console),
+		ConsoleEscaping(EscapeIndent),
+		LogLevels(LogLevelError),
+	)
+	lg.WithError(errors.New("x")).Error("line one\nline two")
+	lg.Shutdown()
+
+	if lines := strings.Split(strings.TrimSuffix(console.String(), "\n"), "\n"); len(lines) != 2 || lines[1] != "\t| line two" {
+		t.Errorf("unexpected console output %q", console.String())
+	}
+
+	file := New(FileEscaping(EscapeControl))
+	defer file.Shutdown()
+
+	out, _ := file.l.configuration.FileEncoder.Encode(nil, testElement(LogLevelInfo, "a\nb", nil))
+	if string(out) != "2020/05/17 10:20:30.123456 a\\nb\n" {
+		t.Errorf("unexpected file output %q", out)
+	}
+}
@@ -0,0 +1,164 @@
+package loge
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+)
+
+// maxTextDepth limits the nesting of the values written by fmt, fmt does not detect cycles
+const maxTextDepth = 16
+
+var (
+	errCyclicValue = errors.New("cyclic value")
+	errDeepValue   = errors.New("max depth exceeded")
+)
+
+// serializationFailures counts the values replaced with placeholders and the entries written with the fallback encoding
+var serializationFailures uint64
+
+// SerializationFailures returns the number of values which could not be serialized since the program start.
+// Such values are replaced with an "[unserializable type: error]" placeholder, the entry is still written.
+func SerializationFailures() uint64 {
+	return atomic.LoadUint64(&serializationFailures)
+}
+
+// badValue counts the failure and returns the placeholder of the value, cause is an error or a recovered panic
+func badValue(v interface{}, cause interface{}) string {
+	atomic.AddUint64(&serializationFailures, 1)
+	return fmt.Sprintf("[unserializable %T: %v]", v, cause)
+}
+
+// safeString returns the text of the value, panics in the Error() and String() methods are recovered
+func safeString(v interface{}) (s string) {
+	defer func() {
+		if r := recover(); r != nil {
+			s = badValue(v, fmt.Sprintf("panic: %v", r))
+		}
+	}()
+
+	switch x := v.(type) {
+	case string:
+		return x
+	case error:
+		return x.Error()
+	case fmt.Stringer:
+		return x.String()
+	}
+
+	if err := checkText(reflect.ValueOf(v), 0, nil); err != nil {
+		return badValue(v, err)
+	}
+
+	s = fmt.Sprint(v)
+	if strings.Contains(s, "(PANIC=") {
+		atomic.AddUint64(&serializationFailures, 1) // panic of a nested value recovered by fmt
+	}
+	return s
+}
+
+// checkText walks the maps, slices, pointers and structs the way fmt does and reports the cycles and the values
+// nested deeper than maxTextDepth, path holds the maps, slices and pointers containing the current value
+func checkText(rv reflect.Value, depth int, path map[uintptr]bool) error {
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Ptr, reflect.Interface, reflect.Struct:
+	default:
+		return nil
+	}
+
+	if depth > 0 && rv.CanInterface() {
+		switch rv.Interface().(type) {
+		case error, fmt.Stringer, fmt.Formatter:
+			return nil // written by the method, panics are recovered by fmt
+		}
+	}
+
+	if depth >= maxTextDepth {
+		return errDeepValue
+	}
+
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Ptr:
+		if rv.IsNil() {
+			return nil
+		}
+
+		if p := rv.Pointer(); p != 0 {
+			if path[p] {
+				return errCyclicValue
+			}
+
+			if path == nil {
+				path = make(map[uintptr]bool)
+			}
+			path[p] = true
+			defer delete(path, p)
+		}
+	}
+
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return checkText(rv.Elem(), depth+1, path)
+	case reflect.Map:
+		iter := rv.MapRange()
+		for iter.Next() {
+			if err := checkText(iter.Value(), depth+1, path); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		switch rv.Type().Elem().Kind() {
+		case reflect.Map, reflect.Slice, reflect.Array, reflect.Ptr, reflect.Interface, reflect.Struct:
+		default:
+			return nil // elements of a scalar type
+		}
+
+		for i := 0; i < rv.Len(); i++ {
+			if err := checkText(rv.Index(i), depth+1, path); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < rv.NumField(); i++ {
+			if err := checkText(rv.Field(i), depth+1, path); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// encodeEntry encodes the entry, if the encoder fails the entry is encoded again with the error note
+// in place of its data, and if that fails too with the text encoder
+func encodeEntry(enc Encoder, buf []byte, be *BufferElement) []byte {
+	out, err := safeEncode(enc, buf, be)
+	if err == nil {
+		return out
+	}
+
+	atomic.AddUint64(&serializationFailures, 1)
+
+	fallback := *be
+	fallback.Data = map[string]interface{}{"encoding_error": err.Error()}
+	fallback.Fields = nil
+
+	if out, err = safeEncode(enc, buf, &fallback); err == nil {
+		return out
+	}
+
+	out, _ = TextEncoder{OptionalData: true}.Encode(buf, &fallback)
+	return out
+}
+
+func safeEncode(enc Encoder, buf []byte, be *BufferElement) (out []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			out, err = buf, fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return enc.Encode(buf, be)
+}
@@ -0,0 +1,106 @@
+package loge
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type panickingValue struct{}
+
+func (panickingValue) String() string {
+	panic("broken String")
+}
+
+func (panickingValue) MarshalJSON() ([]byte, error) {
+	panic("broken MarshalJSON")
+}
+
+type failingEncoder struct{}
+
+func (failingEncoder) Encode(buf []byte, be *BufferElement) ([]byte, error) {
+	if _, ok := be.Data["encoding_error"]; ok {
+		return TextEncoder{OptionalData: true}.Encode(buf, be)
+	}
+	return buf, errors.New("broken encoder")
+}
+
+func TestSerializationFallback(t *testing.T) {
+	type cyclic struct {
+		Next *cyclic
+	}
+	loop := &cyclic{}
+	loop.Next = loop
+
+	var output, custom bytes.Buffer
+
+	lg := New(
+		EnableOutputConsole(true),
+		EnableOutputConsoleInJSONFormat(true),
+		ConsoleOutput(&output),
+		LogLevels(LogLevelInfo),
+		Transports(func(list TransactionList) []Transport {
+			return []Transport{NewWriterTransport(list, &custom, failingEncoder{})}
+		}),
+	)
+
+	failures := SerializationFailures()
+
+	lg.With("channel", make(chan int)).
+		With("func", func() {}).
+		With("loop", loop).
+		With("panic", panickingValue{}).
+		With("uid", 42).
+		Info("bad values")
+	lg.Shutdown()
+
+	entries := decodeEntries(t, &output)
+	if len(entries) != 1 || entries[0].Message != "bad values" || entries[0].Data["uid"] != float64(42) {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+
+	for key, expected := range map[string]string{
+		"channel": "[unserializable chan int: json: unsupported type: chan int]",
+		"func":    "[unserializable func(): json: unsupported type: func()]",
+		"loop":    "[unserializable *loge.cyclic: json: unsupported value: encountered a cycle",
+		"panic":   "[unserializable loge.panickingValue: panic: broken MarshalJSON]",
+	} {
+		if value, _ := entries[0].Data[key].(string); !strings.HasPrefix(value, expected) {
+			t.Errorf("unexpected %s value %q", key, value)
+		}
+	}
+
+	if !strings.HasSuffix(custom.String(), "<encoding_error: broken encoder> bad values\n") {
+		t.Errorf("unexpected fallback output %q", custom.String())
+	}
+
+	text, _ := TextEncoder{OptionalData: true}.Encode(nil, testElement(LogLevelInfo, "text", map[string]interface{}{"panic": panickingValue{}}))
+	if !strings.Contains(string(text), "<panic: [unserializable loge.panickingValue: panic: broken String]> text") {
+		t.Errorf("unexpected text output %q", text)
+	}
+
+	self := map[string]interface{}{}
+	self["self"] = self
+	list := []interface{}{nil}
+	list[0] = list
+
+	before := SerializationFailures()
+	text, _ = TextEncoder{OptionalData: true}.Encode(nil, testElement(LogLevelInfo, "text", map[string]interface{}{"map": self, "list": list}))
+	if !strings.Contains(string(text), "<list: [unserializable []interface {}: cyclic value], map: [unserializable map[string]interface {}: cyclic value]> text") {
+		t.Errorf("unexpected cyclic text output %q", text)
+	}
+
+	logfmt, _ := LogfmtEncoder{}.Encode(nil, testElement(LogLevelInfo, "logfmt", map[string]interface{}{"map": self}))
+	if !strings.Contains(string(logfmt), `map.self.self.self.self.self.self.self.self="[unserializable map[string]interface {}: cyclic value]"`) {
+		t.Errorf("unexpected cyclic logfmt output %q", logfmt)
+	}
+
+	if SerializationFailures()-before != 3 {
+		t.Errorf("unexpected cyclic failure count %d", SerializationFailures()-before)
+	}
+
+	if SerializationFailures()-failures != 9 {
+		t.Errorf("unexpected failure count %d", SerializationFailures()-failures)
+	}
+}
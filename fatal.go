@@ -0,0 +1,87 @@
+package loge
+
+import (
+	"fmt"
+	"os"
+)
+
+// exit terminates the program after Fatal of an entry not created by a logger
+var exit = os.Exit
+
+// ExitFunc returns a function to set the function terminating the program after Fatal (default os.Exit).
+func ExitFunc(exit func(code int)) func(*configuration) *configuration {
+	return func(l *configuration) *configuration {
+		l.ExitFunc = exit
+		return l
+	}
+}
+
+// Flush synchronously writes out the pending entries of the default logger
+func Flush() {
+	std.Flush()
+}
+
+// Flush synchronously writes out the pending entries to the transports implementing Flusher,
+// other transports are notified about the pending entries but not waited for
+func (lg *Logger) Flush() {
+	lg.l.sync()
+}
+
+// terminate is called after the fatal entry is written, it shuts the logger down
+// flushing all the transports and terminates the program
+func (l *logger) terminate() {
+	l.shutdown()
+	l.configuration.ExitFunc(1)
+}
+
+// Fatal creates a new "fatal" log entry, flushes all the outputs and terminates the program with exit code 1
+func Fatal(format string, v ...interface{}) {
+	std.output(LogLevelFatal, format, fmt.Sprintf(format, v...))
+	std.l.terminate()
+}
+
+// Panic creates a new "panic" log entry, flushes the outputs and panics with the message
+func Panic(format string, v ...interface{}) {
+	message := fmt.Sprintf(format, v...)
+	std.output(LogLevelPanic, format, message)
+	std.l.sync()
+	panic(message)
+}
+
+// Fatal creates a new "fatal" log entry, flushes all the outputs and terminates the program with exit code 1
+func (lg *Logger) Fatal(format string, v ...interface{}) {
+	lg.output(LogLevelFatal, format, fmt.Sprintf(format, v...))
+	lg.l.terminate()
+}
+
+// Panic creates a new "panic" log entry, flushes the outputs and panics with the message
+func (lg *Logger) Panic(format string, v ...interface{}) {
+	message := fmt.Sprintf(format, v...)
+	lg.output(LogLevelPanic, format, message)
+	lg.l.sync()
+	panic(message)
+}
+
+// Fatal creates a new "fatal" log entry, flushes all the outputs and terminates the program with exit code 1.
+// The message is written to os.Stderr if the entry was not created by a logger (NewBufferElement).
+func (be *BufferElement) Fatal(format string, v ...interface{}) {
+	message := fmt.Sprintf(format, v...)
+	if be.l == nil {
+		fmt.Fprintln(os.Stderr, message)
+		exit(1)
+		return
+	}
+
+	be.submit(format, message, LogLevelFatal)
+	be.l.terminate()
+}
+
+// Panic creates a new "panic" log entry, flushes the outputs and panics with the message
+func (be *BufferElement) Panic(format string, v ...interface{}) {
+	message := fmt.Sprintf(format, v...)
+	if be.l != nil {
+		be.submit(format, message, LogLevelPanic)
+		be.l.sync()
+	}
+	panic(message)
+}
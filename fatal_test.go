@@ -0,0 +1,74 @@
+package loge
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFatal(t *testing.T) {
+	var custom bytes.Buffer
+	exitCode := -1
+
+	lg := New(
+		TransactionTimeout(time.Hour),
+		ExitFunc(func(code int) { exitCode = code }),
+		Transports(func(list TransactionList) []Transport {
+			return []Transport{NewWriterTransport(list, &custom, TextEncoder{})}
+		}),
+	)
+	defer lg.Shutdown()
+
+	lg.With("uid", 42).Fatal("fatal %s", "failure")
+
+	if exitCode != 1 {
+		t.Errorf("unexpected exit code %d", exitCode)
+	}
+
+	if !strings.HasSuffix(custom.String(), " fatal failure\n") {
+		t.Errorf("entry is not flushed before exit: %q", custom.String())
+	}
+
+	defer func(saved func(int)) { exit = saved }(exit)
+	exitCode = -1
+	exit = func(code int) { exitCode = code }
+
+	NewBufferElement(time.Now(), nil, nil, 0).Fatal("detached entry")
+	if exitCode != 1 {
+		t.Errorf("detached entry does not exit: %d", exitCode)
+	}
+}
+
+func TestPanic(t *testing.T) {
+	dir := t.TempDir()
+
+	lg := New(
+		EnableOutputFile(true),
+		Path(dir),
+		Filename("panic.log"),
+		TransactionTimeout(time.Hour),
+	)
+	defer lg.Shutdown()
+
+	func() {
+		defer func() {
+			if r := recover(); r != "panic failure" {
+				t.Errorf("unexpected panic value %v", r)
+			}
+		}()
+
+		lg.Panic("panic %s", "failure")
+	}()
+
+	content, err := os.ReadFile(filepath.Join(dir, "panic.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasSuffix(string(content), " panic failure\n") {
+		t.Errorf("entry is not flushed before panic: %q", content)
+	}
+}
@@ -0,0 +1,304 @@
+package loge
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+type fieldType uint8
+
+const (
+	fieldAny fieldType = iota
+	fieldString
+	fieldInt
+	fieldFloat
+	fieldBool
+	fieldDuration
+	fieldTime
+	fieldError
+	fieldObject
+)
+
+// Field is a typed key-value pair of the entry created with String, Int and the other constructors.
+// Typed fields are stored in the order they are added and encoded without reflection.
+type Field struct {
+	Key string
+
+	kind    fieldType
+	integer int64
+	str     string
+	iface   interface{}
+}
+
+// String creates a string field
+func String(key string, value string) Field {
+	return Field{Key: key, kind: fieldString, str: value}
+}
+
+// Int creates an integer field
+func Int(key string, value int) Field {
+	return Field{Key: key, kind: fieldInt, integer: int64(value)}
+}
+
+// Int64 creates an integer field
+func Int64(key string, value int64) Field {
+	return Field{Key: key, kind: fieldInt, integer: value}
+}
+
+// Float64 creates a floating point field
+func Float64(key string, value float64) Field {
+	return Field{Key: key, kind: fieldFloat, integer: int64(math.Float64bits(value))}
+}
+
+// Bool creates a boolean field
+func Bool(key string, value bool) Field {
+	var integer int64
+	if value {
+		integer = 1
+	}
+	return Field{Key: key, kind: fieldBool, integer: integer}
+}
+
+// Dur creates a duration field
+func Dur(key string, value time.Duration) Field {
+	return Field{Key: key, kind: fieldDuration, integer: int64(value)}
+}
+
+// Time creates a time field
+func Time(key string, value time.Time) Field {
+	return Field{Key: key, kind: fieldTime, integer: value.UnixNano(), iface: value.Location()}
+}
+
+// Err creates the "error" field, the error is attached to the entry the same way as with WithError
+func Err(err error) Field {
+	if err == nil {
+		return Field{}
+	}
+	return Field{Key: "error", kind: fieldError, iface: err}
+}
+
+// Any creates a field of an arbitrary value, the values of the types supported by the other constructors are stored typed
+func Any(key string, value interface{}) Field {
+	switch v := value.(type) {
+	case string:
+		return String(key, v)
+	case int:
+		return Int(key, v)
+	case int64:
+		return Int64(key, v)
+	case int32:
+		return Int64(key, int64(v))
+	case float64:
+		return Float64(key, v)
+	case bool:
+		return Bool(key, v)
+	case time.Duration:
+		return Dur(key, v)
+	case time.Time:
+		return Time(key, v)
+	case error:
+		return Field{Key: key, kind: fieldError, iface: v}
+	case ObjectMarshaler:
+		return Object(key, v)
+	}
+
+	return Field{Key: key, kind: fieldAny, iface: value}
+}
+
+// Value returns the value of the field
+func (f Field) Value() interface{} {
+	switch f.kind {
+	case fieldString:
+		return f.str
+	case fieldInt:
+		return f.integer
+	case fieldFloat:
+		return math.Float64frombits(uint64(f.integer))
+	case fieldBool:
+		return f.integer != 0
+	case fieldDuration:
+		return time.Duration(f.integer)
+	case fieldTime:
+		return f.time()
+	case fieldObject:
+		return objectMap(f.iface.(ObjectMarshaler), 0)
+	}
+
+	return f.iface
+}
+
+func (f Field) time() time.Time {
+	t := time.Unix(0, f.integer)
+	if loc, ok := f.iface.(*time.Location); ok && loc != nil {
+		t = t.In(loc)
+	}
+	return t
+}
+
+// appendJSON appends the JSON value of the field
+func (f Field) appendJSON(buf []byte) ([]byte, error) {
+	return f.appendJSONDepth(buf, 0)
+}
+
+func (f Field) appendJSONDepth(buf []byte, depth int) ([]byte, error) {
+	switch f.kind {
+	case fieldString:
+		return appendJSONString(buf, f.str), nil
+	case fieldInt, fieldDuration:
+		return strconv.AppendInt(buf, f.integer, 10), nil
+	case fieldFloat:
+		v := math.Float64frombits(uint64(f.integer))
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return appendJSONString(buf, strconv.FormatFloat(v, 'g', -1, 64)), nil
+		}
+		return strconv.AppendFloat(buf, v, 'g', -1, 64), nil
+	case fieldBool:
+		return strconv.AppendBool(buf, f.integer != 0), nil
+	case fieldTime:
+		buf = append(buf, '"')
+		buf = f.time().AppendFormat(buf, time.RFC3339Nano)
+		return append(buf, '"'), nil
+	case fieldError:
+		return appendJSONString(buf, safeString(f.iface)), nil
+	case fieldObject:
+		return appendJSONObject(buf, f.iface.(ObjectMarshaler), depth)
+	}
+
+	return appendJSONValue(buf, f.iface)
+}
+
+// appendJSONValue appends the JSON value of a With() parameter or an untyped field
+func appendJSONValue(buf []byte, v interface{}) ([]byte, error) {
+	if m, ok := v.(ObjectMarshaler); ok {
+		return appendJSONObject(buf, m, 0)
+	}
+
+	return append(buf, marshalJSON(v)...), nil
+}
+
+// marshalJSON never fails, unserializable values and panics in MarshalJSON are replaced with the placeholder string
+func marshalJSON(v interface{}) (value []byte) {
+	defer func() {
+		if r := recover(); r != nil {
+			value = appendJSONString(nil, badValue(v, fmt.Sprintf("panic: %v", r)))
+		}
+	}()
+
+	value, err := json.Marshal(v)
+	if err != nil {
+		return appendJSONString(nil, badValue(v, err))
+	}
+	return value
+}
+
+// appendText appends the human readable value of the field
+func (f Field) appendText(buf []byte) []byte {
+	return f.appendTextDepth(buf, 0)
+}
+
+func (f Field) appendTextDepth(buf []byte, depth int) []byte {
+	switch f.kind {
+	case fieldString:
+		return append(buf, f.str...)
+	case fieldInt:
+		return strconv.AppendInt(buf, f.integer, 10)
+	case fieldFloat:
+		return strconv.AppendFloat(buf, math.Float64frombits(uint64(f.integer)), 'g', -1, 64)
+	case fieldBool:
+		return strconv.AppendBool(buf, f.integer != 0)
+	case fieldDuration:
+		return append(buf, time.Duration(f.integer).String()...)
+	case fieldTime:
+		return f.time().AppendFormat(buf, time.RFC3339Nano)
+	case fieldError:
+		return append(buf, safeString(f.iface)...)
+	case fieldObject:
+		return appendTextObject(buf, f.iface.(ObjectMarshaler), depth)
+	}
+
+	return appendTextValue(buf, f.iface)
+}
+
+// appendTextValue appends the human readable value of a With() parameter or an untyped field
+func appendTextValue(buf []byte, v interface{}) []byte {
+	if m, ok := v.(ObjectMarshaler); ok {
+		return appendTextObject(buf, m, 0)
+	}
+
+	return append(buf, safeString(v)...)
+}
+
+// Typed creates a new log entry of the default logger with the typed fields
+func Typed(fields ...Field) *BufferElement {
+	return std.Typed(fields...)
+}
+
+// Typed creates a new log entry with the typed fields
+func (lg *Logger) Typed(fields ...Field) *BufferElement {
+	return lg.newElement().Typed(fields...)
+}
+
+// Typed extends the log entry with the typed fields, fields are written in the order they are added
+// after the With() parameters.  A typed field overrides the With() parameter of the same key.
+func (be *BufferElement) Typed(fields ...Field) *BufferElement {
+	if be.Fields == nil {
+		be.Fields = make([]Field, 0, len(fields))
+	}
+
+	for _, f := range fields {
+		if f.Key == "" {
+			continue
+		}
+
+		if f.kind == fieldError && be.err == nil {
+			be.err = f.iface.(error)
+		}
+
+		be.Fields = append(be.Fields, f)
+	}
+
+	return be
+}
+
+// Lookup returns the value of the typed field or the With() parameter of the entry
+func (be *BufferElement) Lookup(key string) (interface{}, bool) {
+	for i := len(be.Fields) - 1; i >= 0; i-- {
+		if be.Fields[i].Key == key {
+			return be.Fields[i].Value(), true
+		}
+	}
+
+	v, ok := be.Data[key]
+	return v, ok
+}
+
+// hasField reports if the typed field of the key exists, used to skip the overridden With() parameters
+func (be *BufferElement) hasField(key string) bool {
+	for i := range be.Fields {
+		if be.Fields[i].Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// mergedData returns the With() parameters merged with the typed fields
+func (be *BufferElement) mergedData() map[string]interface{} {
+	if len(be.Fields) == 0 {
+		return be.Data
+	}
+
+	merged := make(map[string]interface{}, len(be.Data)+len(be.Fields))
+	for k, v := range be.Data {
+		merged[k] = v
+	}
+
+	for _, f := range be.Fields {
+		merged[f.Key] = f.Value()
+	}
+
+	return merged
+}
@@ -0,0 +1,116 @@
+package loge
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestTypedFields(t *testing.T) {
+	be := testElement(LogLevelInfo, "request", map[string]interface{}{"uid": 42, "zone": "eu"})
+	be.Typed(
+		String("zone", "us"),
+		Int("status", 200),
+		Dur("elapsed", 1500*time.Millisecond),
+		Bool("cached", false),
+		Float64("ratio", 0.5),
+		Time("at", time.Date(2020, 5, 17, 10, 20, 31, 0, time.UTC)),
+		Err(errors.New("partial \"content\"")),
+		Any("tags", []string{"a", "b"}),
+		Any("count", 3),
+		String("", "ignored"),
+	)
+
+	tests := []struct {
+		name     string
+		encoder  Encoder
+		expected string
+	}{
+		{"text", TextEncoder{OptionalData: true}, "2020/05/17 10:20:30.123456 <uid: 42, zone: us, status: 200, elapsed: 1.5s, cached: false, ratio: 0.5, " +
+			"at: 2020-05-17T10:20:31Z, error: partial \"content\", tags: [a b], count: 3> request\n"},
+		{"json", JSONEncoder{}, `{"time":"2020-05-17T10:20:30.123456Z","msg":"request","level":"info","data":{"uid":42,"zone":"us","status":200,` +
+			`"elapsed":1500000000,"cached":false,"ratio":0.5,"at":"2020-05-17T10:20:31Z","error":"partial \"content\"","tags":["a","b"],"count":3}}` + "\n"},
+		{"flat json", JSONEncoder{FlattenData: true}, `{"time":"2020-05-17T10:20:30.123456Z","msg":"request","level":"info","uid":42,"zone":"us","status":200,` +
+			`"elapsed":1500000000,"cached":false,"ratio":0.5,"at":"2020-05-17T10:20:31Z","error":"partial \"content\"","tags":["a","b"],"count":3}` + "\n"},
+		{"logfmt", LogfmtEncoder{}, `ts=2020-05-17T10:20:30.123456Z level=info msg=request uid=42 zone=us status=200 elapsed=1.5s cached=false ` +
+			`ratio=0.5 at=2020-05-17T10:20:31Z error="partial \"content\"" tags="[a b]" count=3` + "\n"},
+	}
+
+	for _, test := range tests {
+		out, err := test.encoder.Encode(nil, be)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		} else if string(out) != test.expected {
+			t.Errorf("%s: unexpected output %s", test.name, out)
+		}
+	}
+
+	if v, ok := be.Lookup("zone"); !ok || v != "us" {
+		t.Errorf("unexpected zone %v", v)
+	}
+	if v, ok := be.Lookup("uid"); !ok || v != 42 {
+		t.Errorf("unexpected uid %v", v)
+	}
+	if be.err == nil {
+		t.Errorf("error is not attached")
+	}
+
+	out, err := JSONEncoder{}.Encode(nil, testElement(LogLevelInfo, "typed only", nil).Typed(Int("n", 1)))
+	if err != nil || string(out) != `{"time":"2020-05-17T10:20:30.123456Z","msg":"typed only","level":"info","data":{"n":1}}`+"\n" {
+		t.Errorf("unexpected output %s %v", out, err)
+	}
+}
+
+func TestTypedFieldAllocations(t *testing.T) {
+	lg := New(
+		EnableOutputConsole(true),
+		EnableOutputConsoleInJSONFormat(true),
+		ConsoleOutput(ioutil.Discard),
+		LogLevels(LogLevelInfo),
+	)
+	defer lg.Shutdown()
+
+	mapAllocs := testing.AllocsPerRun(100, func() {
+		lg.With("user", "joe").With("status", 200).With("elapsed", time.Second).Info("request")
+	})
+
+	typedAllocs := testing.AllocsPerRun(100, func() {
+		lg.Typed(String("user", "joe"), Int("status", 200), Dur("elapsed", time.Second)).Info("request")
+	})
+
+	if typedAllocs >= mapAllocs {
+		t.Errorf("typed fields allocate %v times, map fields %v times", typedAllocs, mapAllocs)
+	}
+}
+
+func benchmarkLogger() *Logger {
+	return New(
+		EnableOutputConsole(true),
+		EnableOutputConsoleInJSONFormat(true),
+		ConsoleOutput(ioutil.Discard),
+		LogLevels(LogLevelInfo),
+	)
+}
+
+func BenchmarkMapFields(b *testing.B) {
+	lg := benchmarkLogger()
+	defer lg.Shutdown()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lg.With("user", "joe").With("status", 200).With("elapsed", time.Second).Info("request")
+	}
+}
+
+func BenchmarkTypedFields(b *testing.B) {
+	lg := benchmarkLogger()
+	defer lg.Shutdown()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lg.Typed(String("user", "joe"), Int("status", 200), Dur("elapsed", time.Second)).Info("request")
+	}
+}
@@ -21,7 +21,8 @@ type fileOutputTransport struct {
 	path     string
 	filename string
 	rotation bool
-	json     bool
+	encoder  Encoder
+	encoded  []byte
 
 	terminated bool
 
@@ -29,9 +30,10 @@ type fileOutputTransport struct {
 
 	trans       []uint64
 	transLocker sync.Mutex
+	flushLock   sync.Mutex
 }
 
-func newFileTransport(buffer TransactionList, path string, filename string, rotation bool, json bool) *fileOutputTransport {
+func newFileTransport(buffer TransactionList, path string, filename string, rotation bool, encoder Encoder) *fileOutputTransport {
 	ft := &fileOutputTransport{
 		buffer:   buffer,
 		done:     make(chan struct{}),
@@ -40,24 +42,24 @@ func newFileTransport(buffer TransactionList, path string, filename string, rota
 		path:     path,
 		filename: filename,
 		rotation: rotation,
-		json:     json,
+		encoder:  encoder,
 	}
 
+	ft.wg.Add(1)
 	go ft.loop()
 	return ft
 }
 
 func (ft *fileOutputTransport) loop() {
-	ft.wg.Add(1)
 	defer ft.wg.Done()
 
 	for {
 		select {
 		case <-ft.done:
-			ft.flushAll()
+			ft.Flush()
 			return
 		case <-ft.signal:
-			ft.flushAll()
+			ft.Flush()
 		}
 	}
 }
@@ -73,6 +75,13 @@ func (ft *fileOutputTransport) NewTransaction(id uint64) {
 	}
 }
 
+// Flush synchronously writes out all the pending transactions /Flusher
+func (ft *fileOutputTransport) Flush() {
+	ft.flushLock.Lock()
+	ft.flushAll()
+	ft.flushLock.Unlock()
+}
+
 func (ft *fileOutputTransport) Stop() {
 	close(ft.done)
 	ft.wg.Wait()
@@ -141,17 +150,8 @@ func (ft *fileOutputTransport) flushAll() error {
 		tr, ok := ft.buffer.Get(id, true)
 		if ok {
 			for _, be := range tr.Items {
-				if ft.json {
-					json, err := be.Marshal()
-					if err == nil {
-						ft.writer.Write(json)
-						ft.writer.Write([]byte("\n"))
-					}
-				} else {
-					ft.writer.Write(be.Timestring[:])
-					ft.writer.Write([]byte(be.Message))
-					ft.writer.Write([]byte("\n"))
-				}
+				ft.encoded = encodeEntry(ft.encoder, ft.encoded[:0], be)
+				ft.writer.Write(ft.encoded)
 			}
 		}
 	}
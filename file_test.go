@@ -15,7 +15,7 @@ func TestStoragePercent(t *testing.T) {
 func TestFlushAll(t *testing.T) {
 	fmt.Println("Testing flushAll")
 
-	ft := newFileTransport(nil, "./logs", "", true, false)
+	ft := newFileTransport(nil, "./logs", "", true, TextEncoder{})
 	ft.flushAll()
 
 	storageThreshold := 0.0
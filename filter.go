@@ -0,0 +1,71 @@
+package loge
+
+// Filter selects the entries delivered to a transport, it is called from the transport goroutine
+// and must not modify the entry
+type Filter func(be *BufferElement) bool
+
+type filteredList struct {
+	list   TransactionList
+	filter Filter
+}
+
+// LevelFilter returns a filter accepting the entries of the levels in the mask.
+// Plain entries (Printf, Println and the standard log) have no level and are not accepted.
+func LevelFilter(mask uint32) Filter {
+	return func(be *BufferElement) bool {
+		return (be.Level & mask) != 0
+	}
+}
+
+// MinLevelFilter returns a filter accepting the entries at or above the severity of the level
+func MinLevelFilter(level uint32) Filter {
+	threshold := levelSeverity(level)
+	return func(be *BufferElement) bool {
+		return be.Level != 0 && levelSeverity(be.Level) >= threshold
+	}
+}
+
+// FilterTransactions returns a view of the transaction list delivering only the entries accepted by the filter.
+// Transactions returned by Get contain the accepted entries only and may be empty, reference counting is
+// shared with the underlying list.
+func FilterTransactions(list TransactionList, filter Filter) TransactionList {
+	if filter == nil {
+		return list
+	}
+
+	return &filteredList{list: list, filter: filter}
+}
+
+// FileFilter returns a function to set the filter of the entries written to the output file
+func FileFilter(filter Filter) func(*configuration) *configuration {
+	return func(l *configuration) *configuration {
+		l.FileFilter = filter
+		return l
+	}
+}
+
+// Get returns a copy of the transaction containing the accepted entries
+func (fl *filteredList) Get(id uint64, autofree bool) (*Transaction, bool) {
+	tr, ok := fl.list.Get(id, autofree)
+	if !ok {
+		return nil, false
+	}
+
+	filtered := &Transaction{
+		ID:    tr.ID,
+		Items: make([]*BufferElement, 0, len(tr.Items)),
+	}
+
+	for _, be := range tr.Items {
+		if fl.filter(be) {
+			filtered.Items = append(filtered.Items, be)
+		}
+	}
+
+	return filtered, true
+}
+
+// Free decreases the reference count of the transaction in the underlying list
+func (fl *filteredList) Free(id uint64) {
+	fl.list.Free(id)
+}
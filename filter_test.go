@@ -0,0 +1,61 @@
+package loge
+
+import (
+	"bytes"
+	"testing"
+)
+
+type countingHandler struct {
+	transactions int
+	entries      int
+}
+
+func (h *countingHandler) WriteOutTransaction(tr *Transaction) {
+	h.transactions++
+	h.entries += len(tr.Items)
+}
+
+func (h *countingHandler) FlushTransactions() {}
+
+func TestTransportFilters(t *testing.T) {
+	var all, alerts, warnings bytes.Buffer
+	counter := &countingHandler{}
+
+	lg := New(
+		LogLevels(LogLevelInfo|LogLevelDebug|LogLevelWarning|LogLevelError),
+		Transports(func(list TransactionList) []Transport {
+			return []Transport{
+				NewWriterTransport(list, &all, JSONEncoder{}),
+				NewWriterTransport(FilterTransactions(list, LevelFilter(LogLevelError)), &alerts, JSONEncoder{}),
+				NewWriterTransport(FilterTransactions(list, MinLevelFilter(LogLevelWarning)), &warnings, JSONEncoder{}),
+				WrapTransport(FilterTransactions(list, func(be *BufferElement) bool {
+					return be.Data["uid"] != nil
+				}), counter),
+			}
+		}),
+	)
+
+	lg.Printf("plain")
+	lg.Debug("debug")
+	lg.Warn("warning")
+	lg.With("uid", 42).Error("error")
+	lg.Flush()
+	lg.Info("info")
+	lg.Shutdown()
+
+	if entries := decodeEntries(t, &all); len(entries) != 5 {
+		t.Errorf("unexpected unfiltered entries %+v", entries)
+	}
+
+	if entries := decodeEntries(t, &alerts); len(entries) != 1 || entries[0].Message != "error" {
+		t.Errorf("unexpected level filtered entries %+v", entries)
+	}
+
+	if entries := decodeEntries(t, &warnings); len(entries) != 2 || entries[0].Message != "warning" || entries[1].Message != "error" {
+		t.Errorf("unexpected threshold filtered entries %+v", entries)
+	}
+
+	if counter.transactions != 1 || counter.entries != 1 {
+		t.Errorf("unexpected predicate filtered transactions %+v", counter)
+	}
+}
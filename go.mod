@@ -1,6 +1,6 @@
 module github.com/securecollc/loge
 
-go 1.12
+go 1.21
 
 require (
 	github.com/potakhov/cache v0.0.1
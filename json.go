@@ -0,0 +1,323 @@
+package loge
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// Special JSONEncoder.TimeFormat values writing the timestamp as a number
+const (
+	TimeFormatUnix      = "unix"      // seconds since epoch, with fractional part
+	TimeFormatUnixMilli = "unixmilli" // milliseconds since epoch
+	TimeFormatUnixNano  = "unixnano"  // nanoseconds since epoch
+)
+
+// JSONEncoder serializes the entries into JSON format, one entry per line.
+// The zero value produces the same layout as BufferElement.Marshal.
+type JSONEncoder struct {
+	TimeKey     string            // timestamp key (default "time")
+	MessageKey  string            // message key (default "msg")
+	LevelKey    string            // level key (default "level")
+	CallerKey   string            // source location key (default "caller")
+	StackKey    string            // stack trace key (default "stack")
+	ErrorsKey   string            // error chain key (default "error_chain")
+	DataKey     string            // key of the nested optional data (default "data"), ignored if FlattenData is set
+	FlattenData bool              // write optional data fields at the top level instead of nesting them
+	TimeFormat  string            // time layout or one of the TimeFormatUnix constants (default time.RFC3339Nano)
+	LevelNames  map[string]string // level name replacements, the level is omitted if the resulting name is empty
+}
+
+// ECSJSONEncoder returns the JSON layout for Elastic Common Schema
+func ECSJSONEncoder() JSONEncoder {
+	return JSONEncoder{
+		TimeKey:     "@timestamp",
+		MessageKey:  "message",
+		LevelKey:    "log.level",
+		FlattenData: true,
+		TimeFormat:  "2006-01-02T15:04:05.000Z07:00",
+	}
+}
+
+// gcpSeverities are the Cloud Logging severity names of the syslog severities
+var gcpSeverities = [...]string{
+	SyslogEmergency:     "EMERGENCY",
+	SyslogAlert:         "ALERT",
+	SyslogCritical:      "CRITICAL",
+	SyslogError:         "ERROR",
+	SyslogWarning:       "WARNING",
+	SyslogNotice:        "NOTICE",
+	SyslogInformational: "INFO",
+	SyslogDebug:         "DEBUG",
+}
+
+// GCPJSONEncoder returns the JSON layout for Google Cloud Logging structured logs.  Levels are mapped to the severities
+// with SyslogSeverity, custom levels should be registered before the call.
+func GCPJSONEncoder() JSONEncoder {
+	names := map[string]string{"": "DEFAULT"}
+	for _, info := range currentLevels().levels {
+		names[info.name] = gcpSeverities[SyslogSeverity(info.level)]
+	}
+
+	return JSONEncoder{
+		TimeKey:     "time",
+		MessageKey:  "message",
+		LevelKey:    "severity",
+		CallerKey:   "logging.googleapis.com/sourceLocation",
+		FlattenData: true,
+		LevelNames:  names,
+	}
+}
+
+// DatadogJSONEncoder returns the JSON layout for Datadog log management
+func DatadogJSONEncoder() JSONEncoder {
+	return JSONEncoder{
+		TimeKey:     "timestamp",
+		MessageKey:  "message",
+		LevelKey:    "status",
+		FlattenData: true,
+		TimeFormat:  TimeFormatUnixMilli,
+	}
+}
+
+// Encode /Encoder
+func (e JSONEncoder) Encode(buf []byte, be *BufferElement) ([]byte, error) {
+	timeKey := stringOrDefault(e.TimeKey, "time")
+	messageKey := stringOrDefault(e.MessageKey, "msg")
+	levelKey := stringOrDefault(e.LevelKey, "level")
+	callerKey := stringOrDefault(e.CallerKey, "caller")
+	stackKey := stringOrDefault(e.StackKey, "stack")
+	errorsKey := stringOrDefault(e.ErrorsKey, "error_chain")
+
+	buf = append(buf, '{')
+	buf = appendJSONString(buf, timeKey)
+	buf = append(buf, ':')
+	buf = e.appendTime(buf, be.Timestamp)
+
+	buf = append(buf, ',')
+	buf = appendJSONString(buf, messageKey)
+	buf = append(buf, ':')
+	buf = appendJSONString(buf, be.Message)
+
+	level := be.Levelstring
+	if name, ok := e.LevelNames[level]; ok {
+		level = name
+	}
+
+	if level != "" {
+		buf = append(buf, ',')
+		buf = appendJSONString(buf, levelKey)
+		buf = append(buf, ':')
+		buf = appendJSONString(buf, level)
+	}
+
+	if be.Caller != nil {
+		buf = append(buf, ',')
+		buf = appendJSONString(buf, callerKey)
+		buf = append(buf, `:{"file":`...)
+		buf = appendJSONString(buf, be.Caller.File)
+		buf = append(buf, `,"line":`...)
+		buf = strconv.AppendInt(buf, int64(be.Caller.Line), 10)
+		if be.Caller.Function != "" {
+			buf = append(buf, `,"function":`...)
+			buf = appendJSONString(buf, be.Caller.Function)
+		}
+		buf = append(buf, '}')
+	}
+
+	if len(be.Stack) > 0 {
+		stack, err := json.Marshal(be.Stack)
+		if err != nil {
+			return buf, err
+		}
+
+		buf = append(buf, ',')
+		buf = appendJSONString(buf, stackKey)
+		buf = append(buf, ':')
+		buf = append(buf, stack...)
+	}
+
+	if len(be.ErrorChain) > 0 {
+		buf = append(buf, ',')
+		buf = appendJSONString(buf, errorsKey)
+		buf = append(buf, ":["...)
+		for i, e := range be.ErrorChain {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = appendJSONString(buf, e)
+		}
+		buf = append(buf, ']')
+	}
+
+	if len(be.Data) > 0 || len(be.Fields) > 0 {
+		if e.FlattenData {
+			keys := make([]string, 0, len(be.Data))
+			for k := range be.Data {
+				if !be.hasField(k) {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+
+			reserved := func(k string) string {
+				if k == timeKey || k == messageKey || k == levelKey || k == callerKey || k == stackKey || k == errorsKey {
+					return "fields." + k // never override the entry keys
+				}
+				return k
+			}
+
+			for _, k := range keys {
+				buf = append(buf, ',')
+				buf = appendJSONString(buf, reserved(k))
+				buf = append(buf, ':')
+
+				var err error
+				if buf, err = appendJSONValue(buf, be.Data[k]); err != nil {
+					return buf, err
+				}
+			}
+
+			for _, f := range be.Fields {
+				buf = append(buf, ',')
+				buf = appendJSONString(buf, reserved(f.Key))
+				buf = append(buf, ':')
+
+				var err error
+				if buf, err = f.appendJSON(buf); err != nil {
+					return buf, err
+				}
+			}
+		} else {
+			buf = append(buf, ',')
+			buf = appendJSONString(buf, stringOrDefault(e.DataKey, "data"))
+			buf = append(buf, ':')
+
+			var err error
+			if buf, err = appendJSONData(buf, be); err != nil {
+				return buf, err
+			}
+		}
+	}
+
+	buf = append(buf, '}')
+	return append(buf, '\n'), nil
+}
+
+// appendJSONData appends the With() parameters in the sorted order followed by the typed fields as a JSON object
+func appendJSONData(buf []byte, be *BufferElement) ([]byte, error) {
+	keys := make([]string, 0, len(be.Data))
+	for k := range be.Data {
+		if !be.hasField(k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var err error
+	buf = append(buf, '{')
+	for i, k := range keys {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+
+		buf = appendJSONString(buf, k)
+		buf = append(buf, ':')
+		if buf, err = appendJSONValue(buf, be.Data[k]); err != nil {
+			return buf, err
+		}
+	}
+
+	for i, f := range be.Fields {
+		if i > 0 || len(keys) > 0 {
+			buf = append(buf, ',')
+		}
+
+		buf = appendJSONString(buf, f.Key)
+		buf = append(buf, ':')
+		if buf, err = f.appendJSON(buf); err != nil {
+			return buf, err
+		}
+	}
+
+	return append(buf, '}'), nil
+}
+
+func (e JSONEncoder) appendTime(buf []byte, t time.Time) []byte {
+	switch e.TimeFormat {
+	case TimeFormatUnix:
+		return strconv.AppendFloat(buf, float64(t.UnixNano())/1e9, 'f', -1, 64)
+	case TimeFormatUnixMilli:
+		return strconv.AppendInt(buf, t.UnixNano()/1e6, 10)
+	case TimeFormatUnixNano:
+		return strconv.AppendInt(buf, t.UnixNano(), 10)
+	}
+
+	buf = append(buf, '"')
+	buf = t.AppendFormat(buf, stringOrDefault(e.TimeFormat, time.RFC3339Nano))
+	return append(buf, '"')
+}
+
+func stringOrDefault(s string, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString appends the quoted string escaped the same way as encoding/json does
+func appendJSONString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' {
+				i++
+				continue
+			}
+
+			buf = append(buf, s[start:i]...)
+			switch b {
+			case '\\', '"':
+				buf = append(buf, '\\', b)
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', hexDigits[b>>4], hexDigits[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+
+		if r == '\u2028' || r == '\u2029' {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, '\\', 'u', '2', '0', '2', hexDigits[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+
+		i += size
+	}
+
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
@@ -0,0 +1,70 @@
+package loge
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONEncoderCompatibility(t *testing.T) {
+	be := testElement(LogLevelDebug, "quote \" <tag> &   \x01 \xff", map[string]interface{}{"uid": 42, "nick": "pap"})
+
+	expected, err := json.Marshal(be)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := JSONEncoder{}.Encode(nil, be)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(out) != string(expected)+"\n" {
+		t.Errorf("unexpected output %s, expected %s", out, expected)
+	}
+}
+
+func TestJSONEncoderPresets(t *testing.T) {
+	be := testElement(LogLevelWarning, "disk full", map[string]interface{}{"uid": 42, "message": "dup"})
+	plain := testElement(0, "plain", nil)
+
+	tests := []struct {
+		name     string
+		encoder  Encoder
+		be       *BufferElement
+		expected string
+	}{
+		{"nested", JSONEncoder{DataKey: "fields", TimeFormat: TimeFormatUnix}, be, `{"time":1589710830.123456,"msg":"disk full","level":"warning","fields":{"message":"dup","uid":42}}`},
+		{"ecs", ECSJSONEncoder(), be, `{"@timestamp":"2020-05-17T10:20:30.123Z","message":"disk full","log.level":"warning","fields.message":"dup","uid":42}`},
+		{"gcp", GCPJSONEncoder(), be, `{"time":"2020-05-17T10:20:30.123456Z","message":"disk full","severity":"WARNING","fields.message":"dup","uid":42}`},
+		{"gcp default severity", GCPJSONEncoder(), plain, `{"time":"2020-05-17T10:20:30.123456Z","message":"plain","severity":"DEFAULT"}`},
+		{"datadog", DatadogJSONEncoder(), be, `{"timestamp":1589710830123,"message":"disk full","status":"warning","fields.message":"dup","uid":42}`},
+	}
+
+	for _, test := range tests {
+		out, err := test.encoder.Encode(nil, test.be)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		} else if string(out) != test.expected+"\n" {
+			t.Errorf("%s: unexpected output %s", test.name, out)
+		}
+	}
+}
+
+func TestGCPSeverities(t *testing.T) {
+	registerCustomTestLevels(t)
+	encoder := GCPJSONEncoder()
+
+	for level, expected := range map[uint32]string{
+		LogLevelTrace:   "DEBUG",
+		LogLevelInfo:    "INFO",
+		testLevelNotice: "NOTICE",
+		testLevelAudit:  "WARNING",
+		LogLevelError:   "ERROR",
+		LogLevelPanic:   "CRITICAL",
+		LogLevelFatal:   "CRITICAL",
+	} {
+		if name := encoder.LevelNames[levelToString(level)]; name != expected {
+			t.Errorf("unexpected severity %q of level %q", name, levelToString(level))
+		}
+	}
+}
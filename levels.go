@@ -0,0 +1,421 @@
+package loge
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// severity ordinals of the built-in levels, higher is more severe
+const (
+	severityTrace   = 10
+	severityDebug   = 20
+	severityInfo    = 30
+	severityWarning = 40
+	severityError   = 50
+	severityPanic   = 60
+	severityFatal   = 70
+)
+
+type levelInfo struct {
+	level    uint32
+	name     string
+	severity int
+}
+
+// levelRegistry is never modified after it is published, RegisterLevel replaces it with an updated copy
+type levelRegistry struct {
+	levels  []levelInfo // in the bit order
+	byLevel map[uint32]levelInfo
+	byName  map[string]levelInfo
+}
+
+var (
+	registry     atomic.Value // *levelRegistry
+	registryLock sync.Mutex
+)
+
+func init() {
+	r := &levelRegistry{
+		byLevel: make(map[uint32]levelInfo),
+		byName:  make(map[string]levelInfo),
+	}
+
+	r.add(levelInfo{LogLevelInfo, "info", severityInfo})
+	r.add(levelInfo{LogLevelDebug, "debug", severityDebug})
+	r.add(levelInfo{LogLevelTrace, "trace", severityTrace})
+	r.add(levelInfo{LogLevelWarning, "warning", severityWarning})
+	r.add(levelInfo{LogLevelError, "error", severityError})
+	r.add(levelInfo{LogLevelPanic, "panic", severityPanic})
+	r.add(levelInfo{LogLevelFatal, "fatal", severityFatal})
+
+	registry.Store(r)
+}
+
+func currentLevels() *levelRegistry {
+	return registry.Load().(*levelRegistry)
+}
+
+func (r *levelRegistry) add(info levelInfo) {
+	r.levels = append(r.levels, info)
+	sort.Slice(r.levels, func(x int, y int) bool {
+		return r.levels[x].level < r.levels[y].level
+	})
+	r.byLevel[info.level] = info
+	r.byName[info.name] = info
+}
+
+// RegisterLevel registers a custom log level.  Level must be a single bit not used by other levels, name must be
+// unique and is used in the output.  Severity orders the levels, built-in levels use trace 10, debug 20, info 30,
+// warning 40, error 50, panic 60 and fatal 70, severity must be positive.  Custom levels are logged with Log() and enabled with the same
+// mask functions as the built-in levels.  Levels should be registered before the logger is initialized.
+func RegisterLevel(name string, level uint32, severity int) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || strings.ContainsAny(name, ", ") {
+		return fmt.Errorf("invalid log level name %q", name)
+	}
+
+	if level == 0 || (level&(level-1)) != 0 {
+		return fmt.Errorf("log level %q must be a single bit, got %#x", name, level)
+	}
+
+	if severity <= 0 {
+		return fmt.Errorf("log level %q severity must be positive, got %d", name, severity)
+	}
+
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	current := currentLevels()
+	if existing, ok := current.byLevel[level]; ok {
+		return fmt.Errorf("log level bit %#x is already used by %q", level, existing.name)
+	}
+
+	if _, ok := current.byName[name]; ok || name == "warn" {
+		return fmt.Errorf("log level %q is already registered", name)
+	}
+
+	r := &levelRegistry{
+		levels:  make([]levelInfo, len(current.levels), len(current.levels)+1),
+		byLevel: make(map[uint32]levelInfo, len(current.byLevel)+1),
+		byName:  make(map[string]levelInfo, len(current.byName)+1),
+	}
+
+	copy(r.levels, current.levels)
+	for k, v := range current.byLevel {
+		r.byLevel[k] = v
+	}
+	for k, v := range current.byName {
+		r.byName[k] = v
+	}
+
+	r.add(levelInfo{level, name, severity})
+	registry.Store(r)
+
+	return nil
+}
+
+func levelToString(level uint32) string {
+	return currentLevels().byLevel[level].name
+}
+
+func levelSeverity(level uint32) int {
+	return currentLevels().byLevel[level].severity
+}
+
+// Syslog severities
+const (
+	SyslogEmergency     = 0
+	SyslogAlert         = 1
+	SyslogCritical      = 2
+	SyslogError         = 3
+	SyslogWarning       = 4
+	SyslogNotice        = 5
+	SyslogInformational = 6
+	SyslogDebug         = 7
+)
+
+// SyslogSeverity maps the level to the syslog severity using its severity ordinal:
+// below 30 (trace, debug) is debug, 30-34 (info) is informational, 35-39 is notice,
+// 40-49 (warning) is warning, 50-54 (error) is error, 55-79 (panic, fatal) is critical,
+// 80-89 is alert and 90 or above is emergency.  Entries without a level are informational.
+func SyslogSeverity(level uint32) int {
+	severity := levelSeverity(level)
+	switch {
+	case severity == 0:
+		return SyslogInformational
+	case severity < severityInfo:
+		return SyslogDebug
+	case severity < 35:
+		return SyslogInformational
+	case severity < severityWarning:
+		return SyslogNotice
+	case severity < severityError:
+		return SyslogWarning
+	case severity < 55:
+		return SyslogError
+	case severity < 80:
+		return SyslogCritical
+	case severity < 90:
+		return SyslogAlert
+	default:
+		return SyslogEmergency
+	}
+}
+
+func (l *logger) enabled(level uint32) bool {
+	if (atomic.LoadUint32(&l.levels) & level) != 0 {
+		return true
+	}
+
+	threshold := atomic.LoadInt32(&l.minSeverity)
+	return threshold > 0 && int32(levelSeverity(level)) >= threshold
+}
+
+func (l *logger) setMinLevel(level uint32) {
+	var threshold int32
+	if level != 0 {
+		threshold = int32(levelSeverity(level))
+	}
+	atomic.StoreInt32(&l.minSeverity, threshold)
+}
+
+func (l *logger) minLevelName() string {
+	threshold := int(atomic.LoadInt32(&l.minSeverity))
+	if threshold == 0 {
+		return ""
+	}
+
+	for _, info := range currentLevels().levels {
+		if info.severity == threshold {
+			return info.name
+		}
+	}
+
+	return strconv.Itoa(threshold)
+}
+
+func (l *logger) setLevels(mask uint32) {
+	atomic.StoreUint32(&l.levels, mask)
+}
+
+func (l *logger) updateLevels(set uint32, clear uint32) uint32 {
+	for {
+		old := atomic.LoadUint32(&l.levels)
+		updated := (old | set) &^ clear
+		if atomic.CompareAndSwapUint32(&l.levels, old, updated) {
+			return updated
+		}
+	}
+}
+
+// SetMinLevel enables all the levels at or above the severity of the given level in the default logger, 0 disables the threshold
+func SetMinLevel(level uint32) {
+	std.SetMinLevel(level)
+}
+
+// Levels returns the active log levels mask of the default logger
+func Levels() uint32 {
+	return std.Levels()
+}
+
+// SetLevels replaces the active log levels mask of the default logger
+func SetLevels(mask uint32) {
+	std.SetLevels(mask)
+}
+
+// EnableLevel enables the logging of the given level(s) in the default logger
+func EnableLevel(level uint32) {
+	std.EnableLevel(level)
+}
+
+// DisableLevel disables the logging of the given level(s) in the default logger
+func DisableLevel(level uint32) {
+	std.DisableLevel(level)
+}
+
+// SetMinLevel enables all the levels at or above the severity of the given level in addition
+// to the levels mask, 0 disables the threshold.  Safe to call while the logger is in use.
+func (lg *Logger) SetMinLevel(level uint32) {
+	lg.l.setMinLevel(level)
+}
+
+// Levels returns the active log levels mask
+func (lg *Logger) Levels() uint32 {
+	return atomic.LoadUint32(&lg.l.levels)
+}
+
+// SetLevels replaces the active log levels mask, safe to call while the logger is in use
+func (lg *Logger) SetLevels(mask uint32) {
+	lg.l.setLevels(mask)
+}
+
+// EnableLevel enables the logging of the given level(s), safe to call while the logger is in use
+func (lg *Logger) EnableLevel(level uint32) {
+	lg.l.updateLevels(level, 0)
+}
+
+// DisableLevel disables the logging of the given level(s), safe to call while the logger is in use
+func (lg *Logger) DisableLevel(level uint32) {
+	lg.l.updateLevels(0, level)
+}
+
+func levelFromString(name string) (uint32, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "warn" {
+		name = "warning"
+	}
+
+	info, ok := currentLevels().byName[name]
+	return info.level, ok
+}
+
+// parseLevels parses a comma separated list of level names or a numeric mask
+func parseLevels(s string) (uint32, error) {
+	if mask, err := strconv.ParseUint(strings.TrimSpace(s), 0, 32); err == nil {
+		return uint32(mask), nil
+	}
+
+	var mask uint32
+	for _, name := range strings.Split(s, ",") {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+
+		level, ok := levelFromString(name)
+		if !ok {
+			return 0, fmt.Errorf("unknown log level %q", name)
+		}
+		mask |= level
+	}
+
+	return mask, nil
+}
+
+func levelsToStrings(mask uint32) []string {
+	names := make([]string, 0)
+	for _, info := range currentLevels().levels {
+		if (mask & info.level) != 0 {
+			names = append(names, info.name)
+		}
+	}
+	return names
+}
+
+type levelHandler struct {
+	lg *Logger
+}
+
+type levelHandlerState struct {
+	Levels     uint32   `json:"levels"`
+	Enabled    []string `json:"enabled"`
+	MinLevel   string   `json:"min_level,omitempty"`
+	Components string   `json:"components,omitempty"`
+}
+
+// LevelHandler returns an http.Handler to show and change the active log levels of the default logger
+func LevelHandler() http.Handler {
+	return std.LevelHandler()
+}
+
+// LevelHandler returns an http.Handler to show and change the active log levels.
+// GET returns the current mask and the list of enabled levels in JSON format.
+// PUT and POST accept "levels" to replace the mask, "enable" and "disable" to change individual levels.
+// Each parameter is either a comma separated list of level names or a numeric mask.  "min" sets the
+// severity threshold to the named level, "none" disables it.  "components" replaces the levels of
+// the named loggers, see SetComponentLevels.
+func (lg *Logger) LevelHandler() http.Handler {
+	return &levelHandler{lg: lg}
+}
+
+func (h *levelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		if err := h.update(r); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	mask := h.lg.Levels()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(levelHandlerState{
+		Levels:     mask,
+		Enabled:    levelsToStrings(mask),
+		MinLevel:   h.lg.l.minLevelName(),
+		Components: h.lg.ComponentLevels(),
+	})
+}
+
+func (h *levelHandler) update(r *http.Request) error {
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+
+	var set, enable, disable, threshold uint32
+	var replace, setMin bool
+	var components *componentLevels
+	var err error
+
+	// all the parameters are validated before any of them is applied
+	if v, ok := r.Form["components"]; ok {
+		if components, err = parseComponentLevels(strings.Join(v, ",")); err != nil {
+			return err
+		}
+	}
+
+	if v := r.Form.Get("min"); v != "" {
+		if v != "none" {
+			var ok bool
+			if threshold, ok = levelFromString(v); !ok {
+				return fmt.Errorf("unknown log level %q", v)
+			}
+		}
+		setMin = true
+	}
+
+	if v := r.Form.Get("levels"); v != "" {
+		if set, err = parseLevels(v); err != nil {
+			return err
+		}
+		replace = true
+	}
+
+	if v := r.Form.Get("enable"); v != "" {
+		if enable, err = parseLevels(v); err != nil {
+			return err
+		}
+	}
+
+	if v := r.Form.Get("disable"); v != "" {
+		if disable, err = parseLevels(v); err != nil {
+			return err
+		}
+	}
+
+	if components != nil {
+		h.lg.l.components.Store(components)
+	}
+
+	if replace {
+		h.lg.l.setLevels((set | enable) &^ disable)
+	} else {
+		h.lg.l.updateLevels(enable, disable)
+	}
+
+	if setMin {
+		h.lg.l.setMinLevel(threshold)
+	}
+
+	return nil
+}
@@ -0,0 +1,194 @@
+package loge
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRuntimeLevels(t *testing.T) {
+	var output bytes.Buffer
+
+	lg := New(
+		EnableOutputConsole(true),
+		ConsoleOutput(&output),
+		LogLevels(LogLevelInfo),
+	)
+	defer lg.Shutdown()
+
+	lg.Debug("first debug")
+	lg.EnableLevel(LogLevelDebug | LogLevelTrace)
+	lg.Debug("second debug")
+	lg.DisableLevel(LogLevelInfo)
+	lg.Info("hidden info")
+
+	if lg.Levels() != LogLevelDebug|LogLevelTrace {
+		t.Errorf("unexpected levels mask %d", lg.Levels())
+	}
+
+	if strings.Contains(output.String(), "first debug") || !strings.Contains(output.String(), "second debug") || strings.Contains(output.String(), "hidden info") {
+		t.Errorf("unexpected output %q", output.String())
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	lg := New(LogLevels(LogLevelInfo | LogLevelError))
+	defer lg.Shutdown()
+
+	h := lg.LevelHandler()
+
+	request := func(method string, target string) (int, levelHandlerState) {
+		var state levelHandlerState
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+		if rec.Code == http.StatusOK {
+			json.Unmarshal(rec.Body.Bytes(), &state)
+		}
+		return rec.Code, state
+	}
+
+	code, state := request(http.MethodGet, "/")
+	if code != http.StatusOK || state.Levels != LogLevelInfo|LogLevelError || strings.Join(state.Enabled, ",") != "info,error" {
+		t.Errorf("unexpected state %d %+v", code, state)
+	}
+
+	code, state = request(http.MethodPut, "/?enable=debug,trace&disable=info")
+	if code != http.StatusOK || state.Levels != LogLevelDebug|LogLevelTrace|LogLevelError {
+		t.Errorf("unexpected state %d %+v", code, state)
+	}
+
+	code, _ = request(http.MethodPost, "/?levels=verbose")
+	if code != http.StatusBadRequest {
+		t.Errorf("unexpected status %d for unknown level", code)
+	}
+
+	code, state = request(http.MethodPost, "/?levels=warn")
+	if code != http.StatusOK || lg.Levels() != LogLevelWarning {
+		t.Errorf("unexpected state %d %+v", code, state)
+	}
+
+	code, _ = request(http.MethodDelete, "/")
+	if code != http.StatusMethodNotAllowed {
+		t.Errorf("unexpected status %d for DELETE", code)
+	}
+}
+
+const (
+	testLevelNotice uint32 = 1 << 10
+	testLevelAudit  uint32 = 1 << 11
+)
+
+var registerTestLevels sync.Once
+
+func registerCustomTestLevels(t *testing.T) {
+	registerTestLevels.Do(func() {
+		if err := RegisterLevel("notice", testLevelNotice, 35); err != nil {
+			t.Fatal(err)
+		}
+		if err := RegisterLevel("Audit", testLevelAudit, 45); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestCustomLevels(t *testing.T) {
+	registerCustomTestLevels(t)
+
+	if RegisterLevel("critical", LogLevelError, 55) == nil {
+		t.Errorf("bit conflict is not detected")
+	}
+	if RegisterLevel("sentinel", 1<<13, 0) == nil || RegisterLevel("negative", 1<<13, -5) == nil {
+		t.Errorf("non-positive severity is accepted")
+	}
+	if RegisterLevel("notice", 1<<12, 35) == nil {
+		t.Errorf("name conflict is not detected")
+	}
+	if RegisterLevel("critical", 3<<12, 55) == nil {
+		t.Errorf("multiple bits are not detected")
+	}
+
+	var output bytes.Buffer
+
+	lg := New(
+		EnableOutputConsole(true),
+		EnableOutputConsoleInJSONFormat(true),
+		ConsoleOutput(&output),
+		LogLevels(testLevelNotice|LogLevelInfo),
+	)
+	defer lg.Shutdown()
+
+	lg.Log(testLevelNotice, "notice %d", 1)
+	lg.Log(testLevelAudit, "not enabled")
+	lg.EnableLevel(testLevelAudit)
+	lg.With("uid", 42).Log(testLevelAudit, "audit")
+
+	entries := decodeEntries(t, &output)
+	if len(entries) != 2 || entries[0].Level != "notice" || entries[1].Level != "audit" || entries[1].Message != "audit" {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+
+	if strings.Join(levelsToStrings(lg.Levels()), ",") != "info,notice,audit" {
+		t.Errorf("unexpected enabled levels %v", levelsToStrings(lg.Levels()))
+	}
+
+	if mask, err := parseLevels("notice,AUDIT"); err != nil || mask != testLevelNotice|testLevelAudit {
+		t.Errorf("unexpected parsed mask %d %v", mask, err)
+	}
+}
+
+func TestMinLevel(t *testing.T) {
+	var output bytes.Buffer
+
+	lg := New(
+		EnableOutputConsole(true),
+		ConsoleOutput(&output),
+		MinLevel(LogLevelWarning),
+		EnableDebug(),
+	)
+	defer lg.Shutdown()
+
+	lg.Trace("trace hidden")
+	lg.Debug("debug shown")
+	lg.Info("info hidden")
+	lg.Warn("warning shown")
+	lg.Error("error shown")
+
+	lg.SetMinLevel(LogLevelError)
+	lg.Warn("second warning hidden")
+
+	lg.SetMinLevel(0)
+	lg.Error("second error hidden")
+
+	if strings.Contains(output.String(), "hidden") || strings.Count(output.String(), "shown") != 3 {
+		t.Errorf("unexpected output %q", output.String())
+	}
+
+	rec := httptest.NewRecorder()
+	lg.LevelHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/?min=info", nil))
+	if !strings.Contains(rec.Body.String(), `"min_level":"info"`) || !lg.l.enabled(LogLevelWarning) || lg.l.enabled(LogLevelTrace) {
+		t.Errorf("unexpected state %s", rec.Body.String())
+	}
+}
+
+func TestSyslogSeverity(t *testing.T) {
+	expected := map[uint32]int{
+		0:               SyslogInformational,
+		LogLevelTrace:   SyslogDebug,
+		LogLevelDebug:   SyslogDebug,
+		LogLevelInfo:    SyslogInformational,
+		LogLevelWarning: SyslogWarning,
+		LogLevelError:   SyslogError,
+		LogLevelPanic:   SyslogCritical,
+		LogLevelFatal:   SyslogCritical,
+	}
+
+	for level, severity := range expected {
+		if SyslogSeverity(level) != severity {
+			t.Errorf("unexpected syslog severity %d of level %q", SyslogSeverity(level), levelToString(level))
+		}
+	}
+}
@@ -1,11 +1,13 @@
 package loge
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -16,6 +18,8 @@ const (
 	LogLevelTrace   uint32 = 4
 	LogLevelWarning uint32 = 8
 	LogLevelError   uint32 = 16
+	LogLevelPanic   uint32 = 32 // always enabled, see Panic
+	LogLevelFatal   uint32 = 64 // always enabled, see Fatal
 )
 
 // TransportCreator is an interface to create new optional transports when the log is initialized
@@ -31,11 +35,39 @@ type configuration struct {
 	ConsoleOutput            io.Writer              // output writer for console (default os.Stderr)
 	BacklogExpirationTimeout time.Duration          // transaction backlog expiration timeout (default is time.Hour)
 	LogLevels                uint32                 // selectable log levels
+	ComponentLevels          string                 // levels of the named loggers
+	MinLevel                 uint32                 // levels at or above the severity of this level are enabled in addition to LogLevels
 	defaultData              map[string]interface{} // default Data added to each Element
 	Transports               func(list TransactionList) []Transport
-}
-
-var std *logger
+	StackTraceLevel          uint32                                             // capture stack traces for entries at or above the level
+	StackTraceMode           uint32                                             // stack trace capture mode
+	ExitFunc                 func(code int)                                     // function terminating the program after Fatal (default os.Exit)
+	CallerSkip               int                                                // additional stack frames to skip when the caller is captured
+	ConsoleEncoder           Encoder                                            // console output format (default is selected by the work mode)
+	FileEncoder              Encoder                                            // file output format (default is selected by the work mode)
+	SamplingInterval         time.Duration                                      // sampling period, 0 disables sampling
+	SamplingFirst            int                                                // entries passed in each period
+	SamplingThereafter       int                                                // every n-th entry passed after the first ones
+	SamplingSummaryInterval  time.Duration                                      // period of the dropped entries summary (default 1 minute)
+	RateLimitKey             string                                             // Data field grouping the rate limited entries
+	RateLimit                float64                                            // entries per second, 0 disables rate limiting
+	RateLimitBurst           int                                                // token bucket size
+	Deduplicate              bool                                               // collapse the consecutive duplicate entries
+	DeduplicateWindow        time.Duration                                      // maximal delay of the repeated entries report, 0 for none
+	DeduplicateKeys          []string                                           // Data keys compared in addition to the message and level
+	ScopeTrigger             uint32                                             // level flushing the entries buffered in a scope (default error)
+	ScopeSize                int                                                // size limit of the entries buffered in a scope (default 64KB)
+	RedactionRules           []RedactionRule                                    // values replaced before the output
+	FileFilter               Filter                                             // entries written to the output file, nil for all
+	MaxEntrySize             int                                                // estimated entry size limit in bytes, 0 for none
+	MaxEntrySizeMode         uint32                                             // EntrySizeTruncate or EntrySizeSplit
+	ConsoleEscaping          uint32                                             // escaping policy of the default text console output
+	FileEscaping             uint32                                             // escaping policy of the default text file output
+	contextExtractors        []func(ctx context.Context) map[string]interface{} // hooks extracting fields from the context
+	development              bool                                               // development console format, see Development
+}
+
+var std *Logger
 
 const (
 	outputConsole             uint32 = 1
@@ -44,15 +76,19 @@ const (
 	outputIncludeLine         uint32 = 8
 	outputConsoleInJSONFormat uint32 = 16
 	outputConsoleOptionalData uint32 = 32
+	outputInLogfmtFormat      uint32 = 64
 )
 
 func init() {
-	std = newLogger(
-		configuration{
-			Mode:          outputConsole,
-			ConsoleOutput: os.Stderr,
-			defaultData:   make(map[string]interface{}),
-		})
+	std = &Logger{
+		l: newLogger(
+			configuration{
+				Mode:          outputConsole,
+				ConsoleOutput: os.Stderr,
+				defaultData:   make(map[string]interface{}),
+			}),
+	}
+	std.l.redirectStandardLog()
 }
 
 const (
@@ -62,24 +98,35 @@ const (
 )
 
 type logger struct {
-	configuration        configuration
-	writeTimestampBuffer []byte
-	buffer               *buffer
+	configuration configuration
+	levels        uint32        // active log levels mask, accessed atomically
+	minSeverity   int32         // severity threshold, 0 if disabled, accessed atomically
+	components    atomic.Value  // *componentLevels
+	sampler       *sampler      // nil if sampling and rate limiting are disabled
+	dedup         *deduplicator // nil if the deduplication is disabled
+	redactor      *redactor     // nil if no redaction rules are configured
+	buffer        *buffer
 
 	customTimestampBuffer []byte
 	customTimestampLock   sync.Mutex
+
+	consoleBuffer []byte
+	consoleLock   sync.Mutex
+
+	shutdownOnce sync.Once
 }
 
 // Init initializes the library and returns the shutdown handler to defer, must defer call the shutdown handler to ensure log messages are flushed.
+// The default instance created by Init also receives the output of the standard log package.
 func Init(decorators ...func(*configuration) *configuration) func() {
-	c := &configuration{defaultData: make(map[string]interface{})}
-
-	for _, decorator := range decorators {
-		c = decorator(c)
-	}
+	std = New(decorators...)
+	std.l.redirectStandardLog()
+	return std.Shutdown
+}
 
-	std = newLogger(*c)
-	return std.shutdown
+// Default returns the default logger instance used by the package level functions
+func Default() *Logger {
+	return std
 }
 
 // Path returns a function to set the log file path.
@@ -150,6 +197,18 @@ func EnableOutputConsoleInJSONFormat(enable bool) func(*configuration) *configur
 	}
 }
 
+// EnableOutputInLogfmtFormat returns a function to switch the console and file output to logfmt format.
+func EnableOutputInLogfmtFormat(enable bool) func(*configuration) *configuration {
+	return func(l *configuration) *configuration {
+		if enable {
+			l.Mode |= outputInLogfmtFormat
+		} else {
+			l.Mode &^= outputInLogfmtFormat
+		}
+		return l
+	}
+}
+
 // EnableOutputConsoleOptionalData returns a function to enable optional With() fields to the console output if turned on.  By default optional fields are only serialized into JSON format.
 func EnableOutputConsoleOptionalData(enable bool) func(*configuration) *configuration {
 	return func(l *configuration) *configuration {
@@ -162,6 +221,22 @@ func EnableOutputConsoleOptionalData(enable bool) func(*configuration) *configur
 	}
 }
 
+// ConsoleEncoder returns a function to set the console output format, overrides EnableOutputConsoleInJSONFormat and EnableOutputConsoleOptionalData.
+func ConsoleEncoder(e Encoder) func(*configuration) *configuration {
+	return func(l *configuration) *configuration {
+		l.ConsoleEncoder = e
+		return l
+	}
+}
+
+// FileEncoder returns a function to set the file output format, overrides EnableOutputConsoleInJSONFormat.
+func FileEncoder(e Encoder) func(*configuration) *configuration {
+	return func(l *configuration) *configuration {
+		l.FileEncoder = e
+		return l
+	}
+}
+
 // Filename returns a function to set the log file name (ignored if rotation is enabled).
 func Filename(p string) func(*configuration) *configuration {
 	return func(l *configuration) *configuration {
@@ -210,6 +285,15 @@ func LogLevels(p uint32) func(*configuration) *configuration {
 	}
 }
 
+// MinLevel returns a function to enable the logging of all the levels at or above the severity of the given level,
+// the threshold is combined with the LogLevels mask and the Enable...() functions.
+func MinLevel(level uint32) func(*configuration) *configuration {
+	return func(l *configuration) *configuration {
+		l.MinLevel = level
+		return l
+	}
+}
+
 // EnableDebug returns a function to enable the logging of Debug level messages.
 func EnableDebug() func(*configuration) *configuration {
 	return func(l *configuration) *configuration {
@@ -269,13 +353,16 @@ func WithDefault(key string, value interface{}) func(*configuration) *configurat
 func newLogger(c configuration) *logger {
 	l := &logger{
 		configuration: c,
+		levels:        c.LogLevels,
 	}
 
-	flag := 0
-	if (c.Mode & outputIncludeLine) != 0 {
-		flag |= log.Lshortfile
+	if c.MinLevel != 0 {
+		l.minSeverity = int32(levelSeverity(c.MinLevel))
 	}
 
+	l.initComponentLevels()
+	l.redactor = newRedactor(c.RedactionRules)
+
 	if (c.Mode & outputFile) != 0 {
 		validPath := false
 
@@ -303,6 +390,43 @@ func newLogger(c configuration) *logger {
 		l.configuration.ConsoleOutput = os.Stderr
 	}
 
+	if l.configuration.ConsoleEncoder == nil {
+		if c.development {
+			l.configuration.ConsoleEncoder = NewDevEncoder(l.configuration.ConsoleOutput)
+		} else if (c.Mode & outputConsoleInJSONFormat) != 0 {
+			l.configuration.ConsoleEncoder = JSONEncoder{}
+		} else if (c.Mode & outputInLogfmtFormat) != 0 {
+			l.configuration.ConsoleEncoder = LogfmtEncoder{}
+		} else {
+			l.configuration.ConsoleEncoder = TextEncoder{
+				OptionalData: (c.Mode & outputConsoleOptionalData) != 0,
+				Escaping:     c.ConsoleEscaping,
+			}
+		}
+	}
+
+	if l.configuration.FileEncoder == nil {
+		if (c.Mode & outputConsoleInJSONFormat) != 0 {
+			l.configuration.FileEncoder = JSONEncoder{}
+		} else if (c.Mode & outputInLogfmtFormat) != 0 {
+			l.configuration.FileEncoder = LogfmtEncoder{}
+		} else {
+			l.configuration.FileEncoder = TextEncoder{Escaping: c.FileEscaping}
+		}
+	}
+
+	if l.configuration.ScopeTrigger == 0 {
+		l.configuration.ScopeTrigger = LogLevelError
+	}
+
+	if l.configuration.ScopeSize <= 0 {
+		l.configuration.ScopeSize = defaultScopeSize
+	}
+
+	if l.configuration.ExitFunc == nil {
+		l.configuration.ExitFunc = os.Exit
+	}
+
 	if l.configuration.BacklogExpirationTimeout == 0 {
 		l.configuration.BacklogExpirationTimeout = defaultBacklogTimeout
 	}
@@ -314,7 +438,7 @@ func newLogger(c configuration) *logger {
 
 		if (l.configuration.Mode & outputFile) != 0 {
 			outputs = make([]Transport, 1)
-			outputs[0] = newFileTransport(buffer, c.Path, c.Filename, (c.Mode&outputFileRotate) != 0, (c.Mode&outputConsoleInJSONFormat) != 0)
+			outputs[0] = newFileTransport(FilterTransactions(buffer, l.configuration.FileFilter), c.Path, c.Filename, (c.Mode&outputFileRotate) != 0, l.configuration.FileEncoder)
 		} else {
 			outputs = make([]Transport, 0)
 		}
@@ -329,63 +453,109 @@ func newLogger(c configuration) *logger {
 		}
 	}
 
-	log.SetFlags(flag)
-	log.SetOutput(l)
+	l.dedup = newDeduplicator(l)
+	l.sampler = newSampler(l) // the summary goroutine may write to the buffer
 
 	return l
 }
 
+func (l *logger) redirectStandardLog() {
+	flag := 0
+	if (l.configuration.Mode & outputIncludeLine) != 0 {
+		flag |= log.Lshortfile
+	}
+
+	log.SetFlags(flag)
+	log.SetOutput(l)
+}
+
 func (l *logger) shutdown() {
+	l.shutdownOnce.Do(func() {
+		if l.dedup != nil {
+			l.dedup.flush()
+		}
+
+		if l.sampler != nil {
+			l.sampler.shutdown()
+		}
+
+		if l.buffer != nil {
+			l.buffer.shutdown()
+		}
+	})
+}
+
+func (l *logger) sync() {
+	if l.dedup != nil {
+		l.dedup.flush()
+	}
+
 	if l.buffer != nil {
-		l.buffer.shutdown()
+		l.buffer.sync()
 	}
 }
 
 func (l *logger) Write(d []byte) (int, error) {
 	if (l.buffer != nil) || ((l.configuration.Mode & outputConsole) != 0) {
 		t := time.Now()
-		dumpTimeToBuffer(&l.writeTimestampBuffer, t) // don't have to lock this buf here because Write events are serialized
-		l.write(
-			NewBufferElement(t, l.writeTimestampBuffer, d, 0),
-		)
+		var timestamp []byte
+		dumpTimeToBuffer(&timestamp, t)
+		be := NewBufferElement(t, timestamp, d, 0)
+		l.addDefaultData(be)
+		l.write(be)
 	}
 
 	return len(d), nil
 }
 
-func levelToString(level uint32) string {
-	switch level {
-	case LogLevelInfo:
-		return "info"
-	case LogLevelDebug:
-		return "debug"
-	case LogLevelTrace:
-		return "trace"
-	case LogLevelWarning:
-		return "warning"
-	case LogLevelError:
-		return "error"
-	default:
-		return ""
+// write passes the entry through the sampling, LogValuer resolution, redaction, scope and deduplication stages and delivers it to the outputs
+func (l *logger) write(be *BufferElement) {
+	if l.sampler != nil && !l.sampler.admit(be) {
+		return
 	}
+
+	be.resolveValues()
+
+	if l.redactor != nil {
+		l.redactor.redact(be)
+	}
+
+	if l.configuration.MaxEntrySize > 0 {
+		for _, part := range l.limitSize(be) {
+			l.forward(part)
+		}
+		return
+	}
+
+	l.forward(be)
 }
 
-func (l *logger) write(be *BufferElement) {
+// forward passes the entry to its scope, or emits it if the entry is not held
+func (l *logger) forward(be *BufferElement) {
+	if be.scope != nil && be.scope.hold(be) {
+		return
+	}
+
+	l.emit(be)
+}
+
+// emit passes the entry through the deduplication stage and delivers it to the outputs
+func (l *logger) emit(be *BufferElement) {
+	if l.dedup != nil {
+		l.dedup.write(be)
+		return
+	}
+
+	l.deliver(be)
+}
+
+// deliver writes the entry to the console and the buffer of the transports
+func (l *logger) deliver(be *BufferElement) {
 	if (l.configuration.Mode & outputConsole) != 0 {
-		if (l.configuration.Mode & outputConsoleInJSONFormat) != 0 {
-			json, err := be.Marshal()
-			if err == nil {
-				l.configuration.ConsoleOutput.Write(json)
-				l.configuration.ConsoleOutput.Write([]byte("\n"))
-			}
-		} else {
-			l.configuration.ConsoleOutput.Write(be.Timestring[:])
-			if ((l.configuration.Mode & outputConsoleOptionalData) != 0) && (be.Data != nil) {
-				l.configuration.ConsoleOutput.Write([]byte(be.serializeData()))
-			}
-			l.configuration.ConsoleOutput.Write([]byte(be.Message))
-			l.configuration.ConsoleOutput.Write([]byte("\n"))
-		}
+		l.consoleLock.Lock()
+		l.consoleBuffer = encodeEntry(l.configuration.ConsoleEncoder, l.consoleBuffer[:0], be)
+		l.configuration.ConsoleOutput.Write(l.consoleBuffer)
+		l.consoleLock.Unlock()
 	}
 
 	if l.buffer != nil {
@@ -395,68 +565,80 @@ func (l *logger) write(be *BufferElement) {
 	}
 }
 
-func (l *logger) writeLevel(level uint32, message string) {
-	if (l.buffer != nil) || ((l.configuration.Mode & outputConsole) != 0) {
-		l.customTimestampLock.Lock()
-		defer l.customTimestampLock.Unlock()
-		t := time.Now()
-		dumpTimeToBuffer(&l.customTimestampBuffer, t)
-		be := NewBufferElement(t, l.customTimestampBuffer, []byte(message), level)
-		l.write(be)
+func (l *logger) addDefaultData(be *BufferElement) {
+	if len(l.configuration.defaultData) > 0 {
+		be.Data = make(map[string]interface{}, len(l.configuration.defaultData))
+		for k, v := range l.configuration.defaultData {
+			be.Data[k] = v
+		}
 	}
 }
 
 // Printf creates creates a new log entry
 func Printf(format string, v ...interface{}) {
-	std.writeLevel(0, fmt.Sprintf(format, v...))
+	std.output(0, format, fmt.Sprintf(format, v...))
 }
 
 // Println creates creates a new log entry
 func Println(v ...interface{}) {
-	std.writeLevel(0, fmt.Sprintln(v...))
+	std.output(0, "", fmt.Sprintln(v...))
 }
 
 // Info creates creates a new "info" log entry
 func Info(format string, v ...interface{}) {
-	if (std.configuration.LogLevels & LogLevelInfo) != 0 {
-		std.writeLevel(LogLevelInfo, fmt.Sprintf(format, v...))
+	if std.enabled(LogLevelInfo) {
+		std.output(LogLevelInfo, format, fmt.Sprintf(format, v...))
 	}
 }
 
 // Debug creates creates a new "debug" log entry
 func Debug(format string, v ...interface{}) {
-	if (std.configuration.LogLevels & LogLevelDebug) != 0 {
-		std.writeLevel(LogLevelDebug, fmt.Sprintf(format, v...))
+	if std.enabled(LogLevelDebug) {
+		std.output(LogLevelDebug, format, fmt.Sprintf(format, v...))
 	}
 }
 
 // Trace creates creates a new "trace" log entry
 func Trace(format string, v ...interface{}) {
-	if (std.configuration.LogLevels & LogLevelTrace) != 0 {
-		std.writeLevel(LogLevelTrace, fmt.Sprintf(format, v...))
+	if std.enabled(LogLevelTrace) {
+		std.output(LogLevelTrace, format, fmt.Sprintf(format, v...))
 	}
 }
 
 // Warn creates creates a new "warning" log entry
 func Warn(format string, v ...interface{}) {
-	if (std.configuration.LogLevels & LogLevelWarning) != 0 {
-		std.writeLevel(LogLevelWarning, fmt.Sprintf(format, v...))
+	if std.enabled(LogLevelWarning) {
+		std.output(LogLevelWarning, format, fmt.Sprintf(format, v...))
 	}
 }
 
 // Error creates creates a new "error" log entry
 func Error(format string, v ...interface{}) {
-	if (std.configuration.LogLevels & LogLevelError) != 0 {
-		std.writeLevel(LogLevelError, fmt.Sprintf(format, v...))
+	if std.enabled(LogLevelError) {
+		std.output(LogLevelError, format, fmt.Sprintf(format, v...))
+	}
+}
+
+// Log creates a new log entry of the given level, including custom levels registered with RegisterLevel
+func Log(level uint32, format string, v ...interface{}) {
+	if std.enabled(level) {
+		std.output(level, format, fmt.Sprintf(format, v...))
 	}
 }
 
 // With creates a new log entry with optional parameters
 func With(key string, value interface{}) *BufferElement {
-	be := inPlaceBufferElement(std)
-	if key != "" && value != nil {
-		be.Data[key] = value
-	}
+	return std.With(key, value)
+}
+
+// newEntry creates an entry generated by the logger itself, such as summaries of the dropped entries
+func (l *logger) newEntry(message string, level uint32) *BufferElement {
+	t := time.Now()
+	var timestamp []byte
+	dumpTimeToBuffer(&timestamp, t)
+
+	be := inPlaceBufferElement(l, nil)
+	be.fill(t, timestamp, []byte(message), level)
 	return be
 }
 
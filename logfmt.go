@@ -0,0 +1,158 @@
+package loge
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maximum depth of nested maps flattened into logfmt keys
+const logfmtMaxDepth = 8
+
+// LogfmtEncoder serializes the entries into logfmt format (ts=... level=info msg="..." key=value).
+// Nested maps in Data are flattened into dotted keys, keys are written in sorted order followed by the typed fields.
+type LogfmtEncoder struct{}
+
+type logfmtPair struct {
+	key   string
+	value interface{}
+}
+
+// Encode /Encoder
+func (e LogfmtEncoder) Encode(buf []byte, be *BufferElement) ([]byte, error) {
+	buf = append(buf, "ts="...)
+	buf = be.Timestamp.AppendFormat(buf, time.RFC3339Nano)
+
+	if be.Levelstring != "" {
+		buf = append(buf, " level="...)
+		buf = appendLogfmtValue(buf, be.Levelstring)
+	}
+
+	if be.Caller != nil {
+		buf = append(buf, " caller="...)
+		buf = appendLogfmtValue(buf, be.Caller.String())
+	}
+
+	buf = append(buf, " msg="...)
+	buf = appendLogfmtValue(buf, be.Message)
+
+	if len(be.Data) > 0 {
+		pairs := make([]logfmtPair, 0, len(be.Data))
+		for k, v := range be.Data {
+			if !be.hasField(k) {
+				pairs = flattenLogfmt(pairs, k, v, 0)
+			}
+		}
+
+		sort.Slice(pairs, func(x int, y int) bool {
+			return pairs[x].key < pairs[y].key
+		})
+
+		for _, p := range pairs {
+			buf = append(buf, ' ')
+			buf = appendLogfmtKey(buf, p.key)
+			buf = append(buf, '=')
+			buf = appendLogfmtValue(buf, logfmtString(p.value))
+		}
+	}
+
+	var value []byte
+	for _, f := range be.Fields {
+		buf = append(buf, ' ')
+		buf = appendLogfmtKey(buf, f.Key)
+		buf = append(buf, '=')
+
+		if f.kind == fieldAny {
+			buf = appendLogfmtValue(buf, logfmtString(f.iface))
+		} else {
+			value = f.appendText(value[:0])
+			buf = appendLogfmtValue(buf, string(value))
+		}
+	}
+
+	if len(be.ErrorChain) > 0 {
+		buf = append(buf, " error_chain="...)
+		buf = appendLogfmtValue(buf, strings.Join(be.ErrorChain, "; "))
+	}
+
+	if len(be.Stack) > 0 {
+		buf = append(buf, " stack="...)
+		buf = appendLogfmtValue(buf, string(appendStackFrames(nil, be.Stack)))
+	}
+
+	return append(buf, '\n'), nil
+}
+
+func flattenLogfmt(pairs []logfmtPair, key string, value interface{}, depth int) []logfmtPair {
+	if value == nil || depth >= logfmtMaxDepth {
+		return append(pairs, logfmtPair{key: key, value: value})
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String || rv.Len() == 0 {
+		return append(pairs, logfmtPair{key: key, value: value})
+	}
+
+	iter := rv.MapRange()
+	for iter.Next() {
+		pairs = flattenLogfmt(pairs, key+"."+iter.Key().String(), iter.Value().Interface(), depth+1)
+	}
+
+	return pairs
+}
+
+func logfmtString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case ObjectMarshaler:
+		return string(appendTextObject(nil, v, 0))
+	default:
+		return safeString(v)
+	}
+}
+
+func appendLogfmtKey(buf []byte, key string) []byte {
+	if key == "" {
+		return append(buf, '_')
+	}
+
+	for _, r := range key {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || !unicode.IsPrint(r) {
+			buf = append(buf, '_')
+		} else {
+			buf = utf8.AppendRune(buf, r)
+		}
+	}
+
+	return buf
+}
+
+func appendLogfmtValue(buf []byte, s string) []byte {
+	if logfmtNeedsQuoting(s) {
+		return strconv.AppendQuote(buf, s)
+	}
+	return append(buf, s...)
+}
+
+func logfmtNeedsQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == utf8.RuneError || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+
+	return false
+}
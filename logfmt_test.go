@@ -0,0 +1,49 @@
+package loge
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestLogfmtEncoder(t *testing.T) {
+	be := testElement(LogLevelWarning, "line one\nline \"two\"", map[string]interface{}{
+		"uid":     42,
+		"empty":   "",
+		"err":     errors.New("not found"),
+		"bad key": "a=b",
+		"req": map[string]interface{}{
+			"id":     "r1",
+			"header": map[string]string{"host": "example.com"},
+		},
+	})
+
+	out, err := LogfmtEncoder{}.Encode(nil, be)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `ts=2020-05-17T10:20:30.123456Z level=warning msg="line one\nline \"two\"" bad_key="a=b" empty="" err="not found" req.header.host=example.com req.id=r1 uid=42` + "\n"
+	if string(out) != expected {
+		t.Errorf("unexpected output %q", out)
+	}
+}
+
+func TestLogfmtDefaultData(t *testing.T) {
+	var output bytes.Buffer
+
+	lg := New(
+		EnableOutputConsole(true),
+		EnableOutputInLogfmtFormat(true),
+		ConsoleOutput(&output),
+		LogLevels(LogLevelInfo),
+		WithDefault("process", "calc.exe"),
+	)
+	defer lg.Shutdown()
+
+	lg.Info("started")
+
+	if !bytes.HasSuffix(output.Bytes(), []byte(" level=info msg=started process=calc.exe\n")) {
+		t.Errorf("unexpected output %q", output.String())
+	}
+}
@@ -0,0 +1,154 @@
+package loge
+
+import (
+	"context"
+	"fmt"
+)
+
+// Logger is an independent log instance carrying its own configuration, buffer and transports
+type Logger struct {
+	l          *logger
+	fields     map[string]interface{} // fields added to each entry, never modified after creation
+	callerSkip int                    // additional stack frames to skip when the caller is captured
+	name       string                 // component name, see Named
+	scope      *scope                 // nil unless created by Scoped or WithContext with a scope
+}
+
+// New creates a new independently configured logger instance.  Unlike Init it does not
+// redirect the standard log package output, the caller must call Shutdown to flush pending messages.
+func New(decorators ...func(*configuration) *configuration) *Logger {
+	c := &configuration{defaultData: make(map[string]interface{})}
+
+	for _, decorator := range decorators {
+		c = decorator(c)
+	}
+
+	return &Logger{
+		l: newLogger(*c),
+	}
+}
+
+// WithFields creates a child logger of the default logger adding the fields to each entry
+func WithFields(fields map[string]interface{}) *Logger {
+	return std.WithFields(fields)
+}
+
+// WithFields creates a child logger adding the fields to each entry.  Child loggers share the
+// configuration, buffer and transports with the parent and can be nested and used concurrently.
+// Entry data is merged in the following order, later values override the earlier ones:
+// WithDefault data, parent fields, child fields, With() parameters of the entry.
+func (lg *Logger) WithFields(fields map[string]interface{}) *Logger {
+	merged := make(map[string]interface{}, len(lg.fields)+len(fields))
+	for k, v := range lg.fields {
+		merged[k] = v
+	}
+
+	for k, v := range fields {
+		if k != "" && v != nil {
+			merged[k] = v
+		}
+	}
+
+	child := lg.clone()
+	child.fields = merged
+	return child
+}
+
+// Shutdown flushes pending messages and stops all the transports of the logger.
+// Child loggers share the transports, so shutting down a child shuts down the parent as well.
+func (lg *Logger) Shutdown() {
+	lg.l.shutdown()
+}
+
+// Write implements io.Writer, so the logger can be used as an output of the standard log.Logger
+func (lg *Logger) Write(d []byte) (int, error) {
+	return lg.l.Write(d)
+}
+
+// Printf creates creates a new log entry
+func (lg *Logger) Printf(format string, v ...interface{}) {
+	lg.output(0, format, fmt.Sprintf(format, v...))
+}
+
+// Println creates creates a new log entry
+func (lg *Logger) Println(v ...interface{}) {
+	lg.output(0, "", fmt.Sprintln(v...))
+}
+
+// Info creates creates a new "info" log entry
+func (lg *Logger) Info(format string, v ...interface{}) {
+	if lg.enabled(LogLevelInfo) {
+		lg.output(LogLevelInfo, format, fmt.Sprintf(format, v...))
+	}
+}
+
+// Debug creates creates a new "debug" log entry
+func (lg *Logger) Debug(format string, v ...interface{}) {
+	if lg.enabled(LogLevelDebug) {
+		lg.output(LogLevelDebug, format, fmt.Sprintf(format, v...))
+	}
+}
+
+// Trace creates creates a new "trace" log entry
+func (lg *Logger) Trace(format string, v ...interface{}) {
+	if lg.enabled(LogLevelTrace) {
+		lg.output(LogLevelTrace, format, fmt.Sprintf(format, v...))
+	}
+}
+
+// Warn creates creates a new "warning" log entry
+func (lg *Logger) Warn(format string, v ...interface{}) {
+	if lg.enabled(LogLevelWarning) {
+		lg.output(LogLevelWarning, format, fmt.Sprintf(format, v...))
+	}
+}
+
+// Error creates creates a new "error" log entry
+func (lg *Logger) Error(format string, v ...interface{}) {
+	if lg.enabled(LogLevelError) {
+		lg.output(LogLevelError, format, fmt.Sprintf(format, v...))
+	}
+}
+
+// Log creates a new log entry of the given level, including custom levels registered with RegisterLevel
+func (lg *Logger) Log(level uint32, format string, v ...interface{}) {
+	if lg.enabled(level) {
+		lg.output(level, format, fmt.Sprintf(format, v...))
+	}
+}
+
+// With creates a new log entry with optional parameters
+func (lg *Logger) With(key string, value interface{}) *BufferElement {
+	return lg.newElement().With(key, value)
+}
+
+// clone creates a child logger sharing the configuration, buffer and transports
+func (lg *Logger) clone() *Logger {
+	child := *lg
+	return &child
+}
+
+func (lg *Logger) enabled(level uint32) bool {
+	return lg.l.enabledFor(lg.name, level) || lg.scope.active()
+}
+
+// enabledCtx also enables all the levels in the scope attached to the context
+func (lg *Logger) enabledCtx(ctx context.Context, level uint32) bool {
+	return lg.enabled(level) || (ctx != nil && scopeFromContext(ctx).active())
+}
+
+func (lg *Logger) newElement() *BufferElement {
+	be := inPlaceBufferElement(lg.l, lg.fields)
+	be.callerSkip = lg.callerSkip
+	be.component = lg.name
+	be.scope = lg.scope
+	return be
+}
+
+// output must be called directly from the public API functions, see callerFrames
+func (lg *Logger) output(level uint32, template string, message string) {
+	be := lg.newElement()
+	be.template = template
+	lg.l.annotate(be, level, lg.callerSkip)
+	lg.l.submit(be, message, level)
+}
@@ -0,0 +1,136 @@
+package loge
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestIndependentLoggers(t *testing.T) {
+	var auditOutput, appOutput bytes.Buffer
+
+	audit := New(
+		EnableOutputConsole(true),
+		ConsoleOutput(&auditOutput),
+		LogLevels(LogLevelInfo),
+	)
+	defer audit.Shutdown()
+
+	app := New(
+		EnableOutputConsole(true),
+		ConsoleOutput(&appOutput),
+		LogLevels(LogLevelDebug),
+	)
+	defer app.Shutdown()
+
+	audit.Info("audit %d", 1)
+	audit.Debug("audit debug")
+	app.Info("app info")
+	app.Debug("app %s", "debug")
+
+	if !strings.Contains(auditOutput.String(), "audit 1") || strings.Contains(auditOutput.String(), "audit debug") {
+		t.Errorf("unexpected audit output: %q", auditOutput.String())
+	}
+
+	if strings.Contains(appOutput.String(), "app info") || !strings.Contains(appOutput.String(), "app debug") {
+		t.Errorf("unexpected app output: %q", appOutput.String())
+	}
+
+	if strings.Contains(auditOutput.String(), "app") || strings.Contains(appOutput.String(), "audit") {
+		t.Errorf("outputs are mixed between logger instances")
+	}
+}
+
+func TestConcurrentWriters(t *testing.T) {
+	var output bytes.Buffer
+
+	lg := New(
+		EnableOutputConsole(false),
+		Transports(func(list TransactionList) []Transport {
+			return []Transport{NewWriterTransport(list, &output, JSONEncoder{})}
+		}),
+	)
+
+	var wg sync.WaitGroup
+	for _, prefix := range []string{"a ", "b "} {
+		std := log.New(lg, prefix, 0)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				std.Print("message")
+			}
+		}()
+	}
+	wg.Wait()
+	lg.Shutdown()
+
+	if entries := decodeEntries(t, &output); len(entries) != 200 {
+		t.Errorf("unexpected number of entries %d", len(entries))
+	}
+}
+
+func TestChildLoggers(t *testing.T) {
+	var output bytes.Buffer
+
+	lg := New(
+		EnableOutputConsole(true),
+		EnableOutputConsoleInJSONFormat(true),
+		ConsoleOutput(&output),
+		LogLevels(LogLevelInfo),
+		WithDefault("component", "default"),
+		WithDefault("ip", "127.0.0.1"),
+	)
+	defer lg.Shutdown()
+
+	child := lg.WithFields(map[string]interface{}{"component": "http", "request_id": "r1"})
+	nested := child.WithFields(map[string]interface{}{"request_id": "r2"})
+
+	child.Info("first")
+	nested.With("ip", "10.0.0.1").Info("second")
+	child.Info("third")
+
+	entries := decodeEntries(t, &output)
+	if len(entries) != 3 {
+		t.Fatalf("unexpected number of entries %d", len(entries))
+	}
+
+	expected := []map[string]interface{}{
+		{"component": "http", "request_id": "r1", "ip": "127.0.0.1"},
+		{"component": "http", "request_id": "r2", "ip": "10.0.0.1"},
+		{"component": "http", "request_id": "r1", "ip": "127.0.0.1"},
+	}
+
+	for i, entry := range entries {
+		if !reflect.DeepEqual(entry.Data, expected[i]) {
+			t.Errorf("unexpected data of entry %q: %v", entry.Message, entry.Data)
+		}
+	}
+}
+
+type jsonEntry struct {
+	Message string                 `json:"msg"`
+	Level   string                 `json:"level"`
+	Data    map[string]interface{} `json:"data"`
+}
+
+func decodeEntries(t *testing.T, r io.Reader) []jsonEntry {
+	var entries []jsonEntry
+
+	dec := json.NewDecoder(r)
+	for dec.More() {
+		var entry jsonEntry
+		if err := dec.Decode(&entry); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
@@ -0,0 +1,173 @@
+package loge
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"sync"
+)
+
+// componentRule is a single pattern=level rule of the component levels configuration
+type componentRule struct {
+	pattern   string
+	prefix    bool // pattern ends with ".*", matches descendants only
+	threshold int  // minimal enabled severity
+}
+
+// componentLevels is never modified after it is published, SetComponentLevels replaces it
+type componentLevels struct {
+	spec  string
+	rules []componentRule
+	cache sync.Map // component name -> threshold (int), -1 if no rule matches
+}
+
+// ComponentLevels returns a function to set the levels of the named loggers, see SetComponentLevels for the format.
+func ComponentLevels(spec string) func(*configuration) *configuration {
+	return func(l *configuration) *configuration {
+		l.ComponentLevels = spec
+		return l
+	}
+}
+
+// Named creates a named child logger of the default logger
+func Named(name string) *Logger {
+	return std.Named(name)
+}
+
+// Named creates a named child logger, names of nested loggers are joined with a dot.
+// The name is added to each entry as the "logger" field and selects the component level.
+func (lg *Logger) Named(name string) *Logger {
+	if name == "" {
+		return lg
+	}
+
+	if lg.name != "" {
+		name = lg.name + "." + name
+	}
+
+	child := lg.WithFields(map[string]interface{}{"logger": name})
+	child.name = name
+	return child
+}
+
+// SetComponentLevels replaces the levels of the named loggers of the default logger
+func SetComponentLevels(spec string) error {
+	return std.SetComponentLevels(spec)
+}
+
+// SetComponentLevels replaces the levels of the named loggers, safe to call while the logger is in use.
+// The spec is a comma separated list of pattern=level rules, for example "db.*=debug,http=warning".
+// A plain name matches the component and its descendants, "name.*" matches the descendants only
+// and "*" matches all the named loggers.  The most specific pattern wins.  Entries of a matching
+// component are enabled at or above the severity of the rule level regardless of the levels mask,
+// "off" disables the component.  Named loggers without a matching rule use the logger levels.
+func (lg *Logger) SetComponentLevels(spec string) error {
+	c, err := parseComponentLevels(spec)
+	if err != nil {
+		return err
+	}
+
+	lg.l.components.Store(c)
+	return nil
+}
+
+// ComponentLevels returns the current levels specification of the named loggers
+func (lg *Logger) ComponentLevels() string {
+	return lg.l.componentLevels().spec
+}
+
+func parseComponentLevels(spec string) (*componentLevels, error) {
+	c := &componentLevels{spec: strings.TrimSpace(spec)}
+
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		eq := strings.IndexByte(item, '=')
+		if eq <= 0 {
+			return nil, fmt.Errorf("invalid component level %q, expected pattern=level", item)
+		}
+
+		rule := componentRule{pattern: strings.TrimSpace(item[:eq])}
+		name := strings.ToLower(strings.TrimSpace(item[eq+1:]))
+
+		if name == "off" {
+			rule.threshold = math.MaxInt32
+		} else {
+			level, ok := levelFromString(name)
+			if !ok {
+				return nil, fmt.Errorf("unknown log level %q of component %q", name, rule.pattern)
+			}
+			rule.threshold = levelSeverity(level)
+		}
+
+		if strings.HasSuffix(rule.pattern, ".*") {
+			rule.pattern = strings.TrimSuffix(rule.pattern, "*")
+			rule.prefix = true
+		}
+
+		c.rules = append(c.rules, rule)
+	}
+
+	return c, nil
+}
+
+// threshold returns the minimal enabled severity of the component, -1 if no rule matches
+func (c *componentLevels) threshold(name string) int {
+	if t, ok := c.cache.Load(name); ok {
+		return t.(int)
+	}
+
+	threshold := -1
+	specificity := -1
+	for _, rule := range c.rules {
+		matched := false
+		switch {
+		case rule.pattern == "*":
+			matched = true
+		case rule.prefix:
+			matched = strings.HasPrefix(name, rule.pattern)
+		default:
+			matched = name == rule.pattern || strings.HasPrefix(name, rule.pattern+".")
+		}
+
+		ruleSpecificity := len(rule.pattern)
+		if rule.pattern == "*" {
+			ruleSpecificity = 0
+		}
+
+		if matched && ruleSpecificity >= specificity {
+			specificity = ruleSpecificity
+			threshold = rule.threshold
+		}
+	}
+
+	c.cache.Store(name, threshold)
+	return threshold
+}
+
+func (l *logger) componentLevels() *componentLevels {
+	return l.components.Load().(*componentLevels)
+}
+
+func (l *logger) initComponentLevels() {
+	c, err := parseComponentLevels(l.configuration.ComponentLevels)
+	if err != nil {
+		os.Stderr.Write([]byte("Component levels are invalid: " + err.Error() + "\n"))
+		c = &componentLevels{}
+	}
+	l.components.Store(c)
+}
+
+func (l *logger) enabledFor(name string, level uint32) bool {
+	if name != "" {
+		if threshold := l.componentLevels().threshold(name); threshold >= 0 {
+			return levelSeverity(level) >= threshold
+		}
+	}
+
+	return l.enabled(level)
+}
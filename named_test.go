@@ -0,0 +1,101 @@
+package loge
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNamedLoggers(t *testing.T) {
+	var output bytes.Buffer
+
+	lg := New(
+		EnableOutputConsole(true),
+		EnableOutputConsoleInJSONFormat(true),
+		ConsoleOutput(&output),
+		LogLevels(LogLevelInfo|LogLevelWarning|LogLevelError),
+		ComponentLevels("db.*=debug, http=warning"),
+	)
+	defer lg.Shutdown()
+
+	db := lg.Named("db")
+	pool := db.Named("pool")
+	web := lg.Named("http")
+	api := web.Named("api")
+
+	db.Debug("db debug hidden")
+	db.Info("db info")
+	pool.Debug("pool debug")
+	web.Info("http info hidden")
+	api.Warn("api warning")
+	lg.Named("cache").Info("cache info")
+	lg.Debug("root debug hidden")
+
+	entries := decodeEntries(t, &output)
+	expected := []struct{ message, logger string }{
+		{"db info", "db"},
+		{"pool debug", "db.pool"},
+		{"api warning", "http.api"},
+		{"cache info", "cache"},
+	}
+
+	if len(entries) != len(expected) {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	for i, e := range expected {
+		if entries[i].Message != e.message || entries[i].Data["logger"] != e.logger {
+			t.Errorf("unexpected entry %+v, expected %+v", entries[i], e)
+		}
+	}
+}
+
+func TestSetComponentLevels(t *testing.T) {
+	var output bytes.Buffer
+
+	lg := New(
+		EnableOutputConsole(true),
+		ConsoleOutput(&output),
+		LogLevels(LogLevelInfo),
+	)
+	defer lg.Shutdown()
+
+	db := lg.Named("db")
+	if err := lg.SetComponentLevels("*=error,db=trace"); err != nil {
+		t.Fatal(err)
+	}
+
+	db.Trace("db trace shown")
+	lg.Named("http").Info("http info hidden")
+	lg.Info("root info shown")
+
+	if err := lg.SetComponentLevels("db=off"); err != nil {
+		t.Fatal(err)
+	}
+	db.Error("db error hidden")
+
+	if lg.SetComponentLevels("db=verbose") == nil || lg.SetComponentLevels("db") == nil {
+		t.Errorf("invalid spec is accepted")
+	}
+	if lg.ComponentLevels() != "db=off" {
+		t.Errorf("unexpected component levels %q", lg.ComponentLevels())
+	}
+
+	rec := httptest.NewRecorder()
+	lg.LevelHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/?components=db=info", nil))
+	if rec.Code != http.StatusOK || lg.ComponentLevels() != "db=info" {
+		t.Errorf("unexpected state %d %s", rec.Code, rec.Body.String())
+	}
+	db.Info("db info shown")
+
+	rec = httptest.NewRecorder()
+	lg.LevelHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/?components=db=debug&min=bogus", nil))
+	if rec.Code != http.StatusBadRequest || lg.ComponentLevels() != "db=info" {
+		t.Errorf("invalid request is applied %d %q", rec.Code, lg.ComponentLevels())
+	}
+
+	if strings.Contains(output.String(), "hidden") || strings.Count(output.String(), "shown") != 3 {
+		t.Errorf("unexpected output %q", output.String())
+	}
+}
@@ -0,0 +1,221 @@
+package loge
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	maxObjectDepth    = 8  // nesting of the ObjectMarshaler values written by the encoders
+	maxLogValuerDepth = 16 // LogValuer returning another LogValuer
+	maxDepthValue     = "[max depth exceeded]"
+)
+
+// LogValuer is implemented by the values resolved only when the entry is written, the values passed to With(),
+// WithFields(), WithDefault() and Any() are replaced with the result of LogValue before the output
+type LogValuer interface {
+	LogValue() interface{}
+}
+
+// Lazy is a LogValuer computing the value with the function.  It can also be passed as an argument of the formatted
+// log functions, the function is called only if the level is enabled.
+type Lazy func() interface{}
+
+// LogValue /LogValuer
+func (f Lazy) LogValue() interface{} {
+	return f()
+}
+
+// Format /fmt.Formatter
+func (f Lazy) Format(s fmt.State, verb rune) {
+	fmt.Fprintf(s, fmt.FormatString(s, verb), f())
+}
+
+// ObjectMarshaler is implemented by the types controlling how they are written by the encoders
+type ObjectMarshaler interface {
+	MarshalLogObject(enc ObjectEncoder) error
+}
+
+// ObjectEncoder receives the fields of an ObjectMarshaler, the fields are written in the order they are added
+type ObjectEncoder interface {
+	AddString(key string, value string)
+	AddInt64(key string, value int64)
+	AddFloat64(key string, value float64)
+	AddBool(key string, value bool)
+	AddDuration(key string, value time.Duration)
+	AddTime(key string, value time.Time)
+	AddObject(key string, value ObjectMarshaler)
+	AddAny(key string, value interface{})
+}
+
+// Object creates a field of the value marshaled with its MarshalLogObject method
+func Object(key string, value ObjectMarshaler) Field {
+	return Field{Key: key, kind: fieldObject, iface: value}
+}
+
+type fieldCollector struct {
+	fields []Field
+}
+
+func (c *fieldCollector) AddString(key string, value string) {
+	c.fields = append(c.fields, String(key, value))
+}
+
+func (c *fieldCollector) AddInt64(key string, value int64) {
+	c.fields = append(c.fields, Int64(key, value))
+}
+
+func (c *fieldCollector) AddFloat64(key string, value float64) {
+	c.fields = append(c.fields, Float64(key, value))
+}
+
+func (c *fieldCollector) AddBool(key string, value bool) {
+	c.fields = append(c.fields, Bool(key, value))
+}
+
+func (c *fieldCollector) AddDuration(key string, value time.Duration) {
+	c.fields = append(c.fields, Dur(key, value))
+}
+
+func (c *fieldCollector) AddTime(key string, value time.Time) {
+	c.fields = append(c.fields, Time(key, value))
+}
+
+func (c *fieldCollector) AddObject(key string, value ObjectMarshaler) {
+	c.fields = append(c.fields, Object(key, value))
+}
+
+func (c *fieldCollector) AddAny(key string, value interface{}) {
+	c.fields = append(c.fields, Any(key, value))
+}
+
+// objectFields collects the fields of the object, the fields added before an error or a panic are returned as well
+func objectFields(m ObjectMarshaler) (fields []Field, err error) {
+	var c fieldCollector
+	defer func() {
+		if r := recover(); r != nil {
+			fields, err = c.fields, fmt.Errorf("%s", badValue(m, fmt.Sprintf("panic: %v", r)))
+		}
+	}()
+
+	err = m.MarshalLogObject(&c)
+	return c.fields, err
+}
+
+// appendJSONObject appends the object as a JSON object, an error is reported in the "error" field of the object
+func appendJSONObject(buf []byte, m ObjectMarshaler, depth int) ([]byte, error) {
+	if depth >= maxObjectDepth {
+		return appendJSONString(buf, maxDepthValue), nil
+	}
+
+	fields, err := objectFields(m)
+	if err != nil {
+		fields = append(fields, String("error", err.Error()))
+	}
+
+	buf = append(buf, '{')
+	for i, f := range fields {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+
+		buf = appendJSONString(buf, f.Key)
+		buf = append(buf, ':')
+
+		if buf, err = f.appendJSONDepth(buf, depth+1); err != nil {
+			return buf, err
+		}
+	}
+
+	return append(buf, '}'), nil
+}
+
+// appendTextObject appends the object as {key: value, ...}
+func appendTextObject(buf []byte, m ObjectMarshaler, depth int) []byte {
+	if depth >= maxObjectDepth {
+		return append(buf, maxDepthValue...)
+	}
+
+	fields, err := objectFields(m)
+	if err != nil {
+		fields = append(fields, String("error", err.Error()))
+	}
+
+	buf = append(buf, '{')
+	for i, f := range fields {
+		if i > 0 {
+			buf = append(buf, ", "...)
+		}
+
+		buf = append(buf, f.Key...)
+		buf = append(buf, ": "...)
+		buf = f.appendTextDepth(buf, depth+1)
+	}
+
+	return append(buf, '}')
+}
+
+// objectMap converts the object into a map, used by the template encoder, Lookup and the redaction
+func objectMap(m ObjectMarshaler, depth int) interface{} {
+	if depth >= maxObjectDepth {
+		return maxDepthValue
+	}
+
+	fields, err := objectFields(m)
+	if err != nil {
+		fields = append(fields, String("error", err.Error()))
+	}
+
+	result := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		if f.kind == fieldObject {
+			result[f.Key] = objectMap(f.iface.(ObjectMarshaler), depth+1)
+		} else {
+			result[f.Key] = f.Value()
+		}
+	}
+
+	return result
+}
+
+// resolveLogValue returns the placeholder string if LogValue panics
+func resolveLogValue(v LogValuer) (value interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			value = badValue(v, fmt.Sprintf("panic: %v", r))
+		}
+	}()
+
+	value = v.LogValue()
+	for depth := 1; depth < maxLogValuerDepth; depth++ {
+		next, ok := value.(LogValuer)
+		if !ok {
+			return value
+		}
+		value = next.LogValue()
+	}
+
+	if _, ok := value.(LogValuer); ok {
+		return maxDepthValue
+	}
+	return value
+}
+
+// resolveValues replaces the LogValuer values of Data and the typed fields with their values
+func (be *BufferElement) resolveValues() {
+	for k, v := range be.Data {
+		if lv, ok := v.(LogValuer); ok {
+			be.Data[k] = resolveLogValue(lv)
+		}
+	}
+
+	for i, f := range be.Fields {
+		if f.kind != fieldAny {
+			continue
+		}
+
+		if lv, ok := f.iface.(LogValuer); ok {
+			be.Fields[i] = Any(f.Key, resolveLogValue(lv))
+		}
+	}
+}
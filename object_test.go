@@ -0,0 +1,111 @@
+package loge
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+type testUser struct {
+	name   string
+	roles  []string
+	parent *testUser
+}
+
+func (u *testUser) MarshalLogObject(enc ObjectEncoder) error {
+	enc.AddString("name", u.name)
+	enc.AddInt64("roles", int64(len(u.roles)))
+	if u.parent != nil {
+		enc.AddObject("parent", u.parent)
+	}
+	if u.name == "" {
+		return errors.New("anonymous user")
+	}
+	return nil
+}
+
+func TestObjectMarshaler(t *testing.T) {
+	admin := &testUser{name: "root", roles: []string{"admin"}}
+	user := &testUser{name: "joe \"jr\"", parent: admin}
+
+	be := testElement(LogLevelInfo, "login", map[string]interface{}{"user": user})
+	be.Typed(Object("admin", admin), Any("anonymous", &testUser{}))
+
+	tests := []struct {
+		name     string
+		encoder  Encoder
+		expected string
+	}{
+		{"text", TextEncoder{OptionalData: true}, `2020/05/17 10:20:30.123456 <user: {name: joe "jr", roles: 0, parent: {name: root, roles: 1}}, ` +
+			`admin: {name: root, roles: 1}, anonymous: {name: , roles: 0, error: anonymous user}> login` + "\n"},
+		{"json", JSONEncoder{}, `{"time":"2020-05-17T10:20:30.123456Z","msg":"login","level":"info","data":{"user":{"name":"joe \"jr\"","roles":0,` +
+			`"parent":{"name":"root","roles":1}},"admin":{"name":"root","roles":1},"anonymous":{"name":"","roles":0,"error":"anonymous user"}}}` + "\n"},
+		{"logfmt", LogfmtEncoder{}, `ts=2020-05-17T10:20:30.123456Z level=info msg=login user="{name: joe \"jr\", roles: 0, parent: {name: root, roles: 1}}" ` +
+			`admin="{name: root, roles: 1}" anonymous="{name: , roles: 0, error: anonymous user}"` + "\n"},
+	}
+
+	for _, test := range tests {
+		out, err := test.encoder.Encode(nil, be)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		} else if string(out) != test.expected {
+			t.Errorf("%s: unexpected output %s", test.name, out)
+		}
+	}
+
+	plain := testElement(LogLevelInfo, "login", map[string]interface{}{"user": admin})
+	if out, _ := plain.Marshal(); string(out) != `{"time":"2020-05-17T10:20:30.123456Z","msg":"login","level":"info","data":{"user":{"name":"root","roles":1}}}` {
+		t.Errorf("unexpected Marshal output %s", out)
+	}
+
+	if v, _ := be.Lookup("admin"); v.(map[string]interface{})["name"] != "root" {
+		t.Errorf("unexpected admin value %+v", v)
+	}
+}
+
+func TestObjectDepthLimit(t *testing.T) {
+	user := &testUser{name: "cyclic"}
+	user.parent = user
+
+	out, err := JSONEncoder{}.Encode(nil, testElement(LogLevelInfo, "cycle", nil).Typed(Object("user", user)))
+	if err != nil || !bytes.Contains(out, []byte(`"parent":"[max depth exceeded]"`)) || bytes.Count(out, []byte(`"name":"cyclic"`)) != maxObjectDepth {
+		t.Errorf("unexpected output %s %v", out, err)
+	}
+
+	text, _ := TextEncoder{OptionalData: true}.Encode(nil, testElement(LogLevelInfo, "cycle", map[string]interface{}{"user": user}))
+	if !bytes.Contains(text, []byte("parent: [max depth exceeded]")) {
+		t.Errorf("unexpected output %s", text)
+	}
+}
+
+func TestLazyValues(t *testing.T) {
+	var output bytes.Buffer
+
+	lg := New(
+		EnableOutputConsole(true),
+		EnableOutputConsoleInJSONFormat(true),
+		ConsoleOutput(&output),
+		LogLevels(LogLevelInfo),
+	)
+	defer lg.Shutdown()
+
+	calls := 0
+	expensive := Lazy(func() interface{} {
+		calls++
+		return calls
+	})
+
+	lg.With("value", expensive).Debug("disabled %v", expensive)
+	if calls != 0 {
+		t.Errorf("lazy value of a disabled entry is resolved %d times", calls)
+	}
+
+	nested := Lazy(func() interface{} { return Lazy(func() interface{} { return time.Duration(0) }) })
+	lg.With("value", expensive).Typed(Any("nested", nested)).Info("formatted %03d", expensive)
+
+	entries := decodeEntries(t, &output)
+	if len(entries) != 1 || entries[0].Message != "formatted 001" || entries[0].Data["value"] != float64(2) || entries[0].Data["nested"] != float64(0) {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+}
@@ -0,0 +1,304 @@
+package loge
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+const (
+	redactedValue     = "[REDACTED]"
+	maxRedactionDepth = 16
+)
+
+// RedactionRule describes the values replaced before the entry is written to the console and the transports.
+// Either Key or Pattern should be set.
+type RedactionRule struct {
+	Key      string         // Data key, map key or struct field name matched case-insensitively at any depth
+	Pattern  *regexp.Regexp // matches masked in the message, the error chain and the string values at any depth
+	KeepLast int            // characters kept at the end of the masked value, 0 replaces the whole value with [REDACTED]
+}
+
+// Redact returns a function to add the redaction rules
+func Redact(rules ...RedactionRule) func(*configuration) *configuration {
+	return func(l *configuration) *configuration {
+		l.RedactionRules = append(l.RedactionRules, rules...)
+		return l
+	}
+}
+
+// RedactKeys returns a function to replace the values of the keys with [REDACTED], see RedactionRule
+func RedactKeys(keys ...string) func(*configuration) *configuration {
+	return func(l *configuration) *configuration {
+		for _, key := range keys {
+			l.RedactionRules = append(l.RedactionRules, RedactionRule{Key: key})
+		}
+		return l
+	}
+}
+
+type redactor struct {
+	keys     map[string]RedactionRule // lower case key -> rule
+	patterns []RedactionRule
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	stringerType      = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// newRedactor returns nil if no rules are configured
+func newRedactor(rules []RedactionRule) *redactor {
+	r := &redactor{keys: make(map[string]RedactionRule)}
+
+	for _, rule := range rules {
+		if rule.Key != "" {
+			r.keys[strings.ToLower(rule.Key)] = rule
+		}
+
+		if rule.Pattern != nil {
+			r.patterns = append(r.patterns, rule)
+		}
+	}
+
+	if len(r.keys) == 0 && len(r.patterns) == 0 {
+		return nil
+	}
+
+	return r
+}
+
+// redact replaces the sensitive values of the entry, the values referenced by Data are never modified,
+// the changed ones are replaced with redacted copies
+func (r *redactor) redact(be *BufferElement) {
+	be.Message = r.redactString(be.Message)
+
+	for i, e := range be.ErrorChain {
+		be.ErrorChain[i] = r.redactString(e)
+	}
+
+	for k, v := range be.Data {
+		if redacted, changed := r.redactField(k, v, 0); changed {
+			be.Data[k] = redacted
+		}
+	}
+
+	for i, f := range be.Fields {
+		_, keyRule := r.keys[strings.ToLower(f.Key)]
+		if !keyRule && f.kind != fieldString && f.kind != fieldError && f.kind != fieldAny && f.kind != fieldObject {
+			continue // numbers, durations and times are redacted by the key rules only
+		}
+
+		if redacted, changed := r.redactField(f.Key, f.Value(), 0); changed {
+			be.Fields[i] = Any(f.Key, redacted)
+		}
+	}
+}
+
+func (r *redactor) redactString(s string) string {
+	for _, rule := range r.patterns {
+		keep := rule.KeepLast
+		s = rule.Pattern.ReplaceAllStringFunc(s, func(match string) string {
+			return mask(match, keep)
+		})
+	}
+
+	return s
+}
+
+func (r *redactor) redactField(key string, v interface{}, depth int) (interface{}, bool) {
+	if rule, ok := r.keys[strings.ToLower(key)]; ok && v != nil {
+		if s, ok := v.(string); ok {
+			return mask(s, rule.KeepLast), true
+		}
+
+		if rule.KeepLast > 0 {
+			return mask(fmt.Sprint(v), rule.KeepLast), true
+		}
+
+		return redactedValue, true
+	}
+
+	return r.redactValue(v, depth)
+}
+
+// redactValue returns the redacted copy of the value and true if anything was redacted
+func (r *redactor) redactValue(v interface{}, depth int) (interface{}, bool) {
+	switch x := v.(type) {
+	case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return v, false
+	case string:
+		s := r.redactString(x)
+		return s, s != x
+	case error:
+		s := r.redactString(x.Error())
+		return s, s != x.Error()
+	case map[string]interface{}:
+		return r.redactMap(x, depth)
+	case ObjectMarshaler:
+		return r.redactMap(objectMap(x, 0).(map[string]interface{}), depth)
+	}
+
+	if depth >= maxRedactionDepth {
+		return v, false
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return v, false
+		}
+		return r.redactValue(rv.Elem().Interface(), depth+1)
+	case reflect.Map:
+		return r.redactReflectMap(rv, depth)
+	case reflect.Struct:
+		return r.redactStruct(rv, depth)
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return v, false
+		}
+		return r.redactSlice(rv, depth)
+	}
+
+	return v, false
+}
+
+func (r *redactor) redactMap(m map[string]interface{}, depth int) (interface{}, bool) {
+	if depth >= maxRedactionDepth {
+		return m, false
+	}
+
+	var copied map[string]interface{}
+	for k, v := range m {
+		redacted, changed := r.redactField(k, v, depth+1)
+		if !changed {
+			continue
+		}
+
+		if copied == nil {
+			copied = make(map[string]interface{}, len(m))
+			for k, v := range m {
+				copied[k] = v
+			}
+		}
+		copied[k] = redacted
+	}
+
+	if copied == nil {
+		return m, false
+	}
+
+	return copied, true
+}
+
+func (r *redactor) redactReflectMap(rv reflect.Value, depth int) (interface{}, bool) {
+	if rv.Type().Key().Kind() != reflect.String {
+		return rv.Interface(), false
+	}
+
+	copied := make(map[string]interface{}, rv.Len())
+	changed := false
+
+	iter := rv.MapRange()
+	for iter.Next() {
+		v := iter.Value().Interface()
+		redacted, ok := r.redactField(iter.Key().String(), v, depth+1)
+		changed = changed || ok
+		copied[iter.Key().String()] = redacted
+	}
+
+	if !changed {
+		return rv.Interface(), false
+	}
+
+	return copied, true
+}
+
+// redactStruct walks the exported fields, the redacted struct is replaced with a map keyed by the JSON field names
+func (r *redactor) redactStruct(rv reflect.Value, depth int) (interface{}, bool) {
+	t := rv.Type()
+	for _, marshaler := range []reflect.Type{jsonMarshalerType, textMarshalerType, stringerType} {
+		if t.Implements(marshaler) || reflect.PtrTo(t).Implements(marshaler) {
+			return rv.Interface(), false
+		}
+	}
+
+	copied := make(map[string]interface{}, t.NumField())
+	changed := false
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+
+		name := field.Name
+		if tag := field.Tag.Get("json"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			if n := strings.Split(tag, ",")[0]; n != "" {
+				name = n
+			}
+		}
+
+		key := name
+		if _, ok := r.keys[strings.ToLower(field.Name)]; ok {
+			key = field.Name
+		}
+
+		redacted, ok := r.redactField(key, rv.Field(i).Interface(), depth+1)
+		changed = changed || ok
+		copied[name] = redacted
+	}
+
+	if !changed {
+		return rv.Interface(), false
+	}
+
+	return copied, true
+}
+
+func (r *redactor) redactSlice(rv reflect.Value, depth int) (interface{}, bool) {
+	var copied []interface{}
+
+	for i := 0; i < rv.Len(); i++ {
+		redacted, changed := r.redactValue(rv.Index(i).Interface(), depth+1)
+		if !changed {
+			continue
+		}
+
+		if copied == nil {
+			copied = make([]interface{}, rv.Len())
+			for j := 0; j < rv.Len(); j++ {
+				copied[j] = rv.Index(j).Interface()
+			}
+		}
+		copied[i] = redacted
+	}
+
+	if copied == nil {
+		return rv.Interface(), false
+	}
+
+	return copied, true
+}
+
+// mask replaces all but the last keep characters with asterisks, short values are masked completely
+func mask(s string, keep int) string {
+	if keep <= 0 {
+		return redactedValue
+	}
+
+	runes := []rune(s)
+	if len(runes) <= keep {
+		return strings.Repeat("*", len(runes))
+	}
+
+	return strings.Repeat("*", len(runes)-keep) + string(runes[len(runes)-keep:])
+}
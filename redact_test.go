@@ -0,0 +1,79 @@
+package loge
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+)
+
+type testCredentials struct {
+	User     string `json:"user"`
+	Password string `json:"pass"`
+	Card     *testCard
+	secret   string
+}
+
+type testCard struct {
+	Number string `json:"number"`
+	Holder string `json:"holder"`
+}
+
+func TestRedaction(t *testing.T) {
+	var output bytes.Buffer
+
+	lg := New(
+		EnableOutputConsole(true),
+		EnableOutputConsoleInJSONFormat(true),
+		ConsoleOutput(&output),
+		LogLevels(LogLevelInfo),
+		RedactKeys("password", "Authorization"),
+		Redact(
+			RedactionRule{Key: "number", KeepLast: 4},
+			RedactionRule{Pattern: regexp.MustCompile(`\b\d{4}[ -]?\d{4}[ -]?\d{4}[ -]?\d{4}\b`), KeepLast: 4},
+			RedactionRule{Pattern: regexp.MustCompile(`token=\w+`)},
+		),
+	)
+	defer lg.Shutdown()
+
+	headers := map[string]interface{}{"AUTHORIZATION": "Bearer abc", "Accept": "*/*"}
+	credentials := &testCredentials{User: "joe", Password: "secret", Card: &testCard{Number: "4111111111111111", Holder: "Joe"}, secret: "x"}
+	tags := []string{"paid with 4111 1111 1111 1111"}
+
+	lg.With("headers", headers).
+		With("credentials", credentials).
+		With("tags", tags).
+		With("count", 3).
+		Info("login with token=abc123 card 4111-1111-1111-1111")
+
+	entries := decodeEntries(t, &output)
+	if len(entries) != 1 {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+
+	e := entries[0]
+	if e.Message != "login with [REDACTED] card ***************1111" {
+		t.Errorf("unexpected message %q", e.Message)
+	}
+
+	if h := e.Data["headers"].(map[string]interface{}); h["AUTHORIZATION"] != "[REDACTED]" || h["Accept"] != "*/*" {
+		t.Errorf("unexpected headers %+v", h)
+	}
+
+	c := e.Data["credentials"].(map[string]interface{})
+	card := c["Card"].(map[string]interface{})
+	if c["user"] != "joe" || c["pass"] != "[REDACTED]" || card["number"] != "************1111" || card["holder"] != "Joe" || c["secret"] != nil {
+		t.Errorf("unexpected credentials %+v", c)
+	}
+
+	if tags := e.Data["tags"].([]interface{}); tags[0] != "paid with ***************1111" {
+		t.Errorf("unexpected tags %+v", tags)
+	}
+
+	if e.Data["count"] != float64(3) {
+		t.Errorf("unexpected count %+v", e.Data["count"])
+	}
+
+	if headers["AUTHORIZATION"] != "Bearer abc" || credentials.Password != "secret" || tags[0] != "paid with 4111 1111 1111 1111" {
+		t.Errorf("original values are modified")
+	}
+}
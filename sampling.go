@@ -0,0 +1,243 @@
+package loge
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultSamplingSummaryInterval = time.Minute
+	maxRateLimitKeys               = 4096
+)
+
+// Sampling returns a function to sample the entries.  In each interval the first entries with the same
+// level and message template pass, then every thereafter-th entry passes, 0 drops the rest of the interval.
+func Sampling(interval time.Duration, first int, thereafter int) func(*configuration) *configuration {
+	return func(l *configuration) *configuration {
+		l.SamplingInterval = interval
+		l.SamplingFirst = first
+		l.SamplingThereafter = thereafter
+		return l
+	}
+}
+
+// RateLimit returns a function to limit the rate of the entries with a token bucket per key, allowing rate
+// entries per second with bursts of up to burst entries.  Key is the name of the Data field the entries are
+// grouped by, entries without the field and all the entries if key is empty are grouped by the message template.
+func RateLimit(key string, rate float64, burst int) func(*configuration) *configuration {
+	return func(l *configuration) *configuration {
+		l.RateLimitKey = key
+		l.RateLimit = rate
+		l.RateLimitBurst = burst
+		return l
+	}
+}
+
+// SamplingSummary returns a function to set the interval of the summary entry reporting the number of
+// entries dropped by sampling and rate limiting (default 1 minute).
+func SamplingSummary(interval time.Duration) func(*configuration) *configuration {
+	return func(l *configuration) *configuration {
+		l.SamplingSummaryInterval = interval
+		return l
+	}
+}
+
+type sampleKey struct {
+	level    uint32
+	template string
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+type sampler struct {
+	logger *logger
+
+	interval    time.Duration
+	first       int
+	thereafter  int
+	counts      map[sampleKey]int
+	periodStart time.Time
+
+	limitKey string
+	rate     float64
+	burst    float64
+	buckets  map[string]*tokenBucket
+
+	lock sync.Mutex
+
+	sampled uint64 // entries dropped by sampling, accessed atomically
+	limited uint64 // entries dropped by rate limiting, accessed atomically
+
+	summaryInterval time.Duration
+	stop            chan struct{}
+	wg              sync.WaitGroup
+}
+
+// newSampler returns nil if neither sampling nor rate limiting is configured
+func newSampler(l *logger) *sampler {
+	c := &l.configuration
+	if c.SamplingInterval <= 0 && c.RateLimit <= 0 {
+		return nil
+	}
+
+	s := &sampler{
+		logger:          l,
+		limitKey:        c.RateLimitKey,
+		rate:            c.RateLimit,
+		burst:           float64(c.RateLimitBurst),
+		summaryInterval: c.SamplingSummaryInterval,
+		stop:            make(chan struct{}),
+	}
+
+	if c.SamplingInterval > 0 {
+		s.interval = c.SamplingInterval
+		s.first = c.SamplingFirst
+		s.thereafter = c.SamplingThereafter
+		s.counts = make(map[sampleKey]int)
+	}
+
+	if c.RateLimit > 0 {
+		if s.burst < 1 {
+			s.burst = 1
+		}
+		s.buckets = make(map[string]*tokenBucket)
+	}
+
+	if s.summaryInterval <= 0 {
+		s.summaryInterval = defaultSamplingSummaryInterval
+	}
+
+	s.wg.Add(1)
+	go s.loop()
+
+	return s
+}
+
+// admit decides if the entry passes sampling and rate limiting, panic and fatal entries always pass
+func (s *sampler) admit(be *BufferElement) bool {
+	if be.Level == LogLevelPanic || be.Level == LogLevelFatal {
+		return true
+	}
+
+	template := be.template
+	if template == "" {
+		template = be.Message
+	}
+
+	now := be.Timestamp
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.counts != nil && !s.sample(sampleKey{be.Level, template}, now) {
+		atomic.AddUint64(&s.sampled, 1)
+		return false
+	}
+
+	if s.buckets != nil {
+		key := template
+		if s.limitKey != "" {
+			if v, ok := be.Lookup(s.limitKey); ok {
+				key = "\x00" + fmt.Sprint(v)
+			}
+		}
+
+		if !s.take(key, now) {
+			atomic.AddUint64(&s.limited, 1)
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *sampler) sample(key sampleKey, now time.Time) bool {
+	if now.Sub(s.periodStart) >= s.interval || now.Before(s.periodStart) {
+		s.periodStart = now
+		s.counts = make(map[sampleKey]int)
+	}
+
+	n := s.counts[key] + 1
+	s.counts[key] = n
+
+	if n <= s.first {
+		return true
+	}
+
+	return s.thereafter > 0 && (n-s.first)%s.thereafter == 0
+}
+
+func (s *sampler) take(key string, now time.Time) bool {
+	b, ok := s.buckets[key]
+	if !ok {
+		if len(s.buckets) >= maxRateLimitKeys {
+			s.expireBuckets(now)
+		}
+
+		b = &tokenBucket{tokens: s.burst, updated: now}
+		s.buckets[key] = b
+	} else if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * s.rate
+		if b.tokens > s.burst {
+			b.tokens = s.burst
+		}
+		b.updated = now
+	}
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+	return true
+}
+
+// expireBuckets removes the buckets refilled to the full burst, they are equivalent to new ones
+func (s *sampler) expireBuckets(now time.Time) {
+	for key, b := range s.buckets {
+		if b.tokens+now.Sub(b.updated).Seconds()*s.rate >= s.burst {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func (s *sampler) loop() {
+	defer s.wg.Done()
+
+	tm := time.NewTicker(s.summaryInterval)
+	defer tm.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			s.summary()
+			return
+		case <-tm.C:
+			s.summary()
+		}
+	}
+}
+
+// summary writes the entry reporting the number of dropped entries since the previous summary, if any
+func (s *sampler) summary() {
+	sampled := atomic.SwapUint64(&s.sampled, 0)
+	limited := atomic.SwapUint64(&s.limited, 0)
+	if sampled+limited == 0 {
+		return
+	}
+
+	be := s.logger.newEntry("log entries dropped by sampling and rate limiting", LogLevelWarning)
+	be.With("sampled", sampled)
+	be.With("rate_limited", limited)
+	s.logger.deliver(be) // the summary itself is never dropped
+}
+
+func (s *sampler) shutdown() {
+	close(s.stop)
+	s.wg.Wait()
+}
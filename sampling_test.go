@@ -0,0 +1,83 @@
+package loge
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestSampling(t *testing.T) {
+	var output bytes.Buffer
+
+	lg := New(
+		EnableOutputConsole(true),
+		EnableOutputConsoleInJSONFormat(true),
+		ConsoleOutput(&output),
+		LogLevels(LogLevelInfo|LogLevelDebug),
+		Sampling(time.Hour, 2, 3),
+	)
+
+	for i := 0; i < 10; i++ {
+		lg.Debug("hot loop %d", i)
+	}
+	lg.Info("hot loop %d", 0)
+	lg.Info("other")
+	lg.Shutdown()
+
+	entries := decodeEntries(t, &output)
+	expected := []string{"hot loop 0", "hot loop 1", "hot loop 4", "hot loop 7", "hot loop 0", "other", "log entries dropped by sampling and rate limiting"}
+	if len(entries) != len(expected) {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+
+	for i, message := range expected {
+		if entries[i].Message != message {
+			t.Errorf("unexpected entry %d %+v", i, entries[i])
+		}
+	}
+
+	if summary := entries[len(entries)-1]; summary.Level != "warning" || summary.Data["sampled"] != float64(6) || summary.Data["rate_limited"] != float64(0) {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	var output bytes.Buffer
+
+	lg := New(
+		EnableOutputConsole(true),
+		EnableOutputConsoleInJSONFormat(true),
+		ConsoleOutput(&output),
+		LogLevels(LogLevelInfo),
+		RateLimit("client", 0.001, 2),
+		SamplingSummary(10*time.Millisecond),
+		ExitFunc(func(int) {}),
+	)
+
+	for i := 0; i < 5; i++ {
+		lg.With("client", "a").Info("request %d", i)
+		lg.With("client", "b").Info("request %d", i)
+		lg.Info("no client %d", i) // grouped by the template
+	}
+	time.Sleep(50 * time.Millisecond)
+	lg.Fatal("fatal entries always pass")
+
+	entries := decodeEntries(t, &output)
+	counts := make(map[interface{}]int)
+	var summary *jsonEntry
+	for i := range entries {
+		if entries[i].Message == "log entries dropped by sampling and rate limiting" {
+			summary = &entries[i]
+			continue
+		}
+		counts[entries[i].Data["client"]]++
+	}
+
+	if counts["a"] != 2 || counts["b"] != 2 || counts[nil] != 3 {
+		t.Errorf("unexpected entry counts %v", counts)
+	}
+
+	if summary == nil || summary.Data["rate_limited"] != float64(9) {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+}
@@ -0,0 +1,143 @@
+package loge
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+const defaultScopeSize = 64 * 1024
+
+type scopeKey struct{}
+
+// FingersCrossed returns a function to configure the scoped buffering, see Scoped.  Trigger is the level
+// flushing the buffered entries of the scope (default error), maxSize limits the size of the entries
+// buffered in each scope in bytes (default 64KB).
+func FingersCrossed(trigger uint32, maxSize int) func(*configuration) *configuration {
+	return func(l *configuration) *configuration {
+		l.ScopeTrigger = trigger
+		l.ScopeSize = maxSize
+		return l
+	}
+}
+
+type scopedEntry struct {
+	be     *BufferElement
+	passes bool // enabled by the logger levels, written when the scope ends without the trigger
+}
+
+// scope holds the entries until an entry at or above the trigger level is written
+type scope struct {
+	lock      sync.Mutex
+	entries   []scopedEntry
+	size      int
+	triggered bool
+	ended     uint32 // accessed atomically
+}
+
+// Scoped creates a child logger of the default logger buffering its entries in a new scope
+func Scoped() (*Logger, func()) {
+	return std.Scoped()
+}
+
+// Scoped creates a child logger buffering its entries in a new scope.  All the levels are enabled in the scope,
+// the entries are held in memory until an entry at or above the trigger level is written, then the buffered
+// entries are written in order followed by the rest of the scope entries.  The returned function ends the scope,
+// if it has not been triggered the held entries not enabled by the logger levels are discarded and the others
+// are written.  When the scope exceeds its size limit the oldest entries are written or discarded the same way.
+func (lg *Logger) Scoped() (*Logger, func()) {
+	s := &scope{}
+	child := lg.clone()
+	child.scope = s
+	return child, s.end
+}
+
+// ScopeContext returns a copy of the context carrying a new scope, the entries of the loggers returned
+// by WithContext, the ...Ctx functions and the slog handler are buffered in the scope, see Logger.Scoped.
+func ScopeContext(ctx context.Context) (context.Context, func()) {
+	s := &scope{}
+	return context.WithValue(ctx, scopeKey{}, s), s.end
+}
+
+func scopeFromContext(ctx context.Context) *scope {
+	s, _ := ctx.Value(scopeKey{}).(*scope)
+	return s
+}
+
+// active is safe to call on nil
+func (s *scope) active() bool {
+	return s != nil && atomic.LoadUint32(&s.ended) == 0
+}
+
+// hold buffers the entry, returns false if the entry should be written immediately
+func (s *scope) hold(be *BufferElement) bool {
+	l := be.l
+	if l == nil || !s.active() {
+		return false
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if atomic.LoadUint32(&s.ended) != 0 {
+		return false
+	}
+
+	if s.triggered {
+		l.emit(be)
+		return true
+	}
+
+	if be.Level == LogLevelPanic || be.Level == LogLevelFatal || levelSeverity(be.Level) >= l.scopeThreshold() {
+		s.triggered = true
+		for _, e := range s.entries {
+			e.be.l.emit(e.be)
+		}
+		s.entries = nil
+		s.size = 0
+		l.emit(be)
+		return true
+	}
+
+	s.entries = append(s.entries, scopedEntry{
+		be:     be,
+		passes: be.Level == 0 || l.enabledFor(be.component, be.Level),
+	})
+	s.size += be.Size()
+
+	maxSize := l.configuration.ScopeSize
+	for len(s.entries) > 1 && s.size > maxSize {
+		s.release(s.entries[0])
+		s.size -= s.entries[0].be.Size()
+		s.entries[0] = scopedEntry{}
+		s.entries = s.entries[1:]
+	}
+
+	return true
+}
+
+// release writes the held entry if it is enabled by the logger levels, otherwise discards it
+func (s *scope) release(e scopedEntry) {
+	if e.passes {
+		e.be.l.emit(e.be)
+	}
+}
+
+func (s *scope) end() {
+	if !atomic.CompareAndSwapUint32(&s.ended, 0, 1) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, e := range s.entries {
+		s.release(e)
+	}
+	s.entries = nil
+	s.size = 0
+}
+
+func (l *logger) scopeThreshold() int {
+	return levelSeverity(l.configuration.ScopeTrigger)
+}
@@ -0,0 +1,82 @@
+package loge
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestScopedLogger(t *testing.T) {
+	var output bytes.Buffer
+
+	lg := New(
+		EnableOutputConsole(true),
+		ConsoleOutput(&output),
+		LogLevels(LogLevelInfo|LogLevelError),
+	)
+	defer lg.Shutdown()
+
+	ok, end := lg.Scoped()
+	ok.Debug("successful debug")
+	ok.Info("successful info")
+	if output.Len() != 0 {
+		t.Errorf("scope entries are not held %q", output.String())
+	}
+	end()
+
+	failed, end := lg.Scoped()
+	failed.Trace("failed trace")
+	failed.With("uid", 42).Debug("failed debug")
+	lg.Info("unscoped info")
+	failed.Error("failed error")
+	failed.Debug("debug after the trigger")
+	end()
+	failed.Debug("debug after the end")
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	expected := []string{"successful info", "unscoped info", "failed trace", "failed debug", "failed error", "debug after the trigger"}
+	if len(lines) != len(expected) {
+		t.Fatalf("unexpected output %q", output.String())
+	}
+
+	for i, message := range expected {
+		if !strings.HasSuffix(lines[i], " "+message) {
+			t.Errorf("unexpected line %q, expected %q", lines[i], message)
+		}
+	}
+}
+
+func TestScopeContext(t *testing.T) {
+	var output bytes.Buffer
+
+	lg := New(
+		EnableOutputConsole(true),
+		ConsoleOutput(&output),
+		LogLevels(LogLevelInfo),
+		FingersCrossed(LogLevelWarning, 100),
+	)
+	defer lg.Shutdown()
+
+	ctx, end := ScopeContext(context.Background())
+	lg.DebugCtx(ctx, "first debug %d", 1)
+	lg.InfoCtx(ctx, "info")
+	lg.DebugCtx(ctx, "second debug %d", 2)
+	lg.DebugCtx(ctx, "third debug %d", 3)
+	lg.DebugCtx(ctx, "fourth debug %d", 4)
+	lg.WithContext(ctx).Warn("warning")
+	end()
+
+	// the oldest entries exceeding the scope size are written if enabled, otherwise discarded
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	expected := []string{"info", "third debug 3", "fourth debug 4", "warning"}
+	if len(lines) != len(expected) {
+		t.Fatalf("unexpected output %q", output.String())
+	}
+
+	for i, message := range expected {
+		if !strings.HasSuffix(lines[i], " "+message) {
+			t.Errorf("unexpected line %q, expected %q", lines[i], message)
+		}
+	}
+}
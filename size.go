@@ -0,0 +1,186 @@
+package loge
+
+import (
+	"time"
+	"unicode/utf8"
+)
+
+// Entry size limit modes
+const (
+	EntrySizeTruncate uint32 = 1 // cut the message and mark the entry with the "truncated" field
+	EntrySizeSplit    uint32 = 2 // write the message in numbered continuation entries with the "part" and "parts" fields
+)
+
+const (
+	estimatedValueSize   = 16  // size of a value of an unknown type
+	estimatedFrameSize   = 16  // line number and separators of a stack frame
+	minEntryPartSize     = 64  // message bytes written in a single part at least
+	maxSizeEstimateDepth = 4   // nested maps counted by Size
+	entrySizeMarkerSize  = 32  // size of the "truncated" and "part" fields
+	truncatedSuffix      = "…" // appended to the truncated message
+)
+
+// MaxEntrySize returns a function to limit the size of the entries estimated by BufferElement.Size, 0 disables the
+// limit.  Only the message is cut, the data fields are written as is: an entry whose data alone exceeds the limit
+// keeps at least 64 bytes of the message in each entry and is written over the limit.  The limit is applied before
+// the entry is written to the console and the transports, so all the outputs receive the same entries.
+func MaxEntrySize(size int, mode uint32) func(*configuration) *configuration {
+	return func(l *configuration) *configuration {
+		l.MaxEntrySize = size
+		l.MaxEntrySizeMode = mode
+		return l
+	}
+}
+
+// Size returns the estimated record size in bytes, the values of the data fields are estimated by their type
+func (be *BufferElement) Size() int {
+	size := dateTimeStringLength + len(be.Message)
+
+	for k, v := range be.Data {
+		if !be.hasField(k) {
+			size += len(k) + estimateSize(v, 0)
+		}
+	}
+
+	for i := range be.Fields {
+		size += len(be.Fields[i].Key) + be.Fields[i].size()
+	}
+
+	for _, e := range be.ErrorChain {
+		size += len(e)
+	}
+
+	for _, frame := range be.Stack {
+		size += len(frame.Function) + len(frame.File) + estimatedFrameSize
+	}
+
+	if be.Caller != nil {
+		size += len(be.Caller.File) + estimatedFrameSize
+	}
+
+	return size
+}
+
+func (f Field) size() int {
+	switch f.kind {
+	case fieldString:
+		return len(f.str)
+	case fieldTime:
+		return len(time.RFC3339Nano)
+	case fieldAny:
+		return estimateSize(f.iface, 0)
+	case fieldError, fieldObject:
+		return estimatedValueSize
+	}
+
+	return 8
+}
+
+// estimateSize returns the size of the strings and byte slices, the other values are estimated without serialization
+func estimateSize(v interface{}, depth int) int {
+	switch x := v.(type) {
+	case nil:
+		return 4
+	case string:
+		return len(x)
+	case []byte:
+		return len(x)
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, time.Duration:
+		return 8
+	case time.Time:
+		return len(time.RFC3339Nano)
+	case []string:
+		size := 0
+		for _, s := range x {
+			size += len(s) + 1
+		}
+		return size
+	case map[string]interface{}:
+		if depth >= maxSizeEstimateDepth {
+			return estimatedValueSize
+		}
+
+		size := 0
+		for k, v := range x {
+			size += len(k) + estimateSize(v, depth+1)
+		}
+		return size
+	}
+
+	return estimatedValueSize
+}
+
+// limitSize returns the entry unchanged if it fits the limit, otherwise the truncated entry or the continuation entries
+func (l *logger) limitSize(be *BufferElement) []*BufferElement {
+	limit := l.configuration.MaxEntrySize
+	size := be.Size()
+	if limit <= 0 || size <= limit {
+		return []*BufferElement{be}
+	}
+
+	// message bytes available in a single entry
+	available := limit - (size - len(be.Message)) - entrySizeMarkerSize
+	if available < minEntryPartSize {
+		available = minEntryPartSize
+	}
+
+	if l.configuration.MaxEntrySizeMode == EntrySizeSplit && len(be.Message) > available {
+		return splitEntry(be, available)
+	}
+
+	if len(be.Message) <= available {
+		return []*BufferElement{be} // the data exceeds the limit, it is never cut
+	}
+
+	be.Message = cutString(be.Message, available-len(truncatedSuffix)) + truncatedSuffix
+	be.Fields = append(be.Fields, Bool("truncated", true), Int("original_size", size))
+	return []*BufferElement{be}
+}
+
+// splitEntry divides the message on the rune boundaries, the stack trace and the error chain are kept in the first part
+func splitEntry(be *BufferElement, available int) []*BufferElement {
+	var messages []string
+	for message := be.Message; len(message) > 0; {
+		part := cutString(message, available)
+		if part == "" {
+			part = message[:available] // a single rune larger than the part, never happens with sane limits
+		}
+		messages = append(messages, part)
+		message = message[len(part):]
+	}
+
+	parts := make([]*BufferElement, len(messages))
+	for i, message := range messages {
+		part := *be
+		part.Message = message
+		part.Fields = make([]Field, len(be.Fields), len(be.Fields)+2)
+		copy(part.Fields, be.Fields)
+		part.Fields = append(part.Fields, Int("part", i+1), Int("parts", len(messages)))
+
+		if i > 0 {
+			part.Stack = nil
+			part.ErrorChain = nil
+		}
+
+		parts[i] = &part
+	}
+
+	return parts
+}
+
+// cutString returns the longest prefix of s not exceeding n bytes which does not end in the middle of a rune
+func cutString(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+
+	if len(s) <= n {
+		return s
+	}
+
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return s[:n]
+}
@@ -0,0 +1,121 @@
+package loge
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSizeCountsData(t *testing.T) {
+	be := &BufferElement{Message: "message"}
+	base := be.Size()
+
+	be.With("payload", strings.Repeat("x", 1000))
+	be.Typed(String("body", strings.Repeat("y", 500)), Int("status", 200))
+
+	if size := be.Size(); size < base+1500 {
+		t.Errorf("data is not counted: %d", size)
+	}
+}
+
+func TestMaxEntrySizeTruncate(t *testing.T) {
+	var console, transport bytes.Buffer
+
+	lg := New(
+		EnableOutputConsole(true),
+		EnableOutputConsoleInJSONFormat(true),
+		ConsoleOutput(&console),
+		LogLevels(LogLevelInfo),
+		MaxEntrySize(256, EntrySizeTruncate),
+		Transports(func(list TransactionList) []Transport {
+			return []Transport{NewWriterTransport(list, &transport, JSONEncoder{})}
+		}),
+	)
+
+	lg.With("request", "r1").Info(strings.Repeat("ü", 1000))
+	lg.Info("short")
+	lg.Shutdown()
+
+	for _, output := range []*bytes.Buffer{&console, &transport} {
+		entries := decodeEntries(t, output)
+		if len(entries) != 2 {
+			t.Fatalf("unexpected entries %+v", entries)
+		}
+
+		long := entries[0]
+		if len(long.Message) > 256 || !utf8.ValidString(long.Message) || !strings.HasSuffix(long.Message, truncatedSuffix) {
+			t.Errorf("message is not truncated: %d bytes", len(long.Message))
+		}
+
+		if long.Data["truncated"] != true || long.Data["original_size"].(float64) < 2000 || long.Data["request"] != "r1" {
+			t.Errorf("unexpected data %v", long.Data)
+		}
+
+		if entries[1].Message != "short" || entries[1].Data["truncated"] != nil {
+			t.Errorf("unexpected entry %+v", entries[1])
+		}
+	}
+}
+
+func TestMaxEntrySizeSplit(t *testing.T) {
+	var output bytes.Buffer
+
+	lg := New(
+		EnableOutputConsole(true),
+		EnableOutputConsoleInJSONFormat(true),
+		ConsoleOutput(&output),
+		LogLevels(LogLevelInfo),
+		MaxEntrySize(200, EntrySizeSplit),
+	)
+
+	message := strings.Repeat("0123456789", 100)
+	lg.With("request", "r1").Info(message)
+	lg.Shutdown()
+
+	entries := decodeEntries(t, &output)
+	if len(entries) < 2 {
+		t.Fatalf("message is not split: %+v", entries)
+	}
+
+	joined := ""
+	for i, entry := range entries {
+		joined += entry.Message
+
+		if entry.Data["part"] != float64(i+1) || entry.Data["parts"] != float64(len(entries)) || entry.Data["request"] != "r1" {
+			t.Errorf("unexpected data of part %d: %v", i, entry.Data)
+		}
+	}
+
+	if joined != message {
+		t.Errorf("parts do not add up to the message")
+	}
+}
+
+func TestMaxEntrySizeData(t *testing.T) {
+	for _, mode := range []uint32{EntrySizeTruncate, EntrySizeSplit} {
+		var output bytes.Buffer
+
+		lg := New(
+			EnableOutputConsole(true),
+			EnableOutputConsoleInJSONFormat(true),
+			ConsoleOutput(&output),
+			LogLevels(LogLevelInfo),
+			MaxEntrySize(100, mode),
+		)
+
+		blob := strings.Repeat("x", 500)
+		lg.With("blob", blob).Info("short")
+		lg.Shutdown()
+
+		entries := decodeEntries(t, &output)
+		if len(entries) != 1 {
+			t.Fatalf("mode %d: unexpected entries %+v", mode, entries)
+		}
+
+		entry := entries[0]
+		if entry.Message != "short" || entry.Data["blob"] != blob || len(entry.Data) != 1 {
+			t.Errorf("mode %d: unexpected entry %q %v", mode, entry.Message, entry.Data)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package loge
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// slogHandler implements slog.Handler on top of the logger buffer and transports.
+// Attributes are stored into BufferElement.Data, groups become nested maps.
+type slogHandler struct {
+	lg     *Logger
+	data   map[string]interface{} // attributes collected with WithAttrs, never modified after creation
+	groups []string               // currently open groups
+}
+
+// SlogHandler returns a slog.Handler writing the records to the default logger
+func SlogHandler() slog.Handler {
+	return std.SlogHandler()
+}
+
+// SlogHandler returns a slog.Handler writing the records to the logger output and transports
+func (lg *Logger) SlogHandler() slog.Handler {
+	return &slogHandler{
+		lg:   lg,
+		data: make(map[string]interface{}),
+	}
+}
+
+func slogLevelToLevel(level slog.Level) uint32 {
+	switch {
+	case level < slog.LevelInfo:
+		return LogLevelDebug
+	case level < slog.LevelWarn:
+		return LogLevelInfo
+	case level < slog.LevelError:
+		return LogLevelWarning
+	default:
+		return LogLevelError
+	}
+}
+
+// Enabled /slog.Handler
+func (h *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.lg.enabledCtx(ctx, slogLevelToLevel(level))
+}
+
+// Handle /slog.Handler
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	l := h.lg.l
+	if (l.buffer == nil) && ((l.configuration.Mode & outputConsole) == 0) {
+		return nil
+	}
+
+	be := h.lg.WithContext(ctx).newElement()
+	if be.Data == nil {
+		be.Data = make(map[string]interface{})
+	}
+	mergeSlogData(be.Data, h.data)
+
+	if r.NumAttrs() > 0 {
+		target := openSlogGroups(be.Data, h.groups)
+		r.Attrs(func(a slog.Attr) bool {
+			addSlogAttr(target, a)
+			return true
+		})
+	}
+
+	t := r.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+
+	var timestamp []byte
+	dumpTimeToBuffer(&timestamp, t)
+	be.fill(t, timestamp, []byte(r.Message), slogLevelToLevel(r.Level))
+	l.annotateFromPC(be, be.Level, r.PC)
+	l.write(be)
+
+	return nil
+}
+
+// WithAttrs /slog.Handler
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	data := make(map[string]interface{})
+	mergeSlogData(data, h.data)
+
+	target := openSlogGroups(data, h.groups)
+	for _, a := range attrs {
+		addSlogAttr(target, a)
+	}
+
+	return &slogHandler{
+		lg:     h.lg,
+		data:   data,
+		groups: h.groups,
+	}
+}
+
+// WithGroup /slog.Handler
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	groups := make([]string, len(h.groups), len(h.groups)+1)
+	copy(groups, h.groups)
+
+	return &slogHandler{
+		lg:     h.lg,
+		data:   h.data,
+		groups: append(groups, name),
+	}
+}
+
+// mergeSlogData deep copies src into dst, nested groups are copied so the source is never shared
+func mergeSlogData(dst map[string]interface{}, src map[string]interface{}) {
+	for k, v := range src {
+		if group, ok := v.(map[string]interface{}); ok {
+			nested := copySlogGroup(dst[k])
+			dst[k] = nested
+			mergeSlogData(nested, group)
+		} else {
+			dst[k] = v
+		}
+	}
+}
+
+// openSlogGroups returns the map of the innermost group, the maps on the path are replaced with copies
+// since they may belong to the caller (With() parameters sharing the group name)
+func openSlogGroups(data map[string]interface{}, groups []string) map[string]interface{} {
+	for _, g := range groups {
+		nested := copySlogGroup(data[g])
+		data[g] = nested
+		data = nested
+	}
+	return data
+}
+
+// copySlogGroup returns a shallow copy of the existing group map or a new map
+func copySlogGroup(v interface{}) map[string]interface{} {
+	existing, _ := v.(map[string]interface{})
+
+	group := make(map[string]interface{}, len(existing))
+	for k, v := range existing {
+		group[k] = v
+	}
+	return group
+}
+
+func addSlogAttr(data map[string]interface{}, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return
+		}
+
+		target := data
+		if a.Key != "" {
+			target = openSlogGroups(data, []string{a.Key})
+		}
+
+		for _, ga := range attrs {
+			addSlogAttr(target, ga)
+		}
+		return
+	}
+
+	value := a.Value.Any()
+	if err, ok := value.(error); ok {
+		value = err.Error()
+	}
+	data[a.Key] = value
+}
@@ -0,0 +1,95 @@
+package loge
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"testing"
+)
+
+func TestSlogHandler(t *testing.T) {
+	var output bytes.Buffer
+
+	lg := New(
+		EnableOutputConsole(true),
+		EnableOutputConsoleInJSONFormat(true),
+		ConsoleOutput(&output),
+		LogLevels(LogLevelInfo|LogLevelWarning),
+		WithDefault("ip", "127.0.0.1"),
+	)
+	defer lg.Shutdown()
+
+	sl := slog.New(lg.SlogHandler())
+	sl.Debug("not enabled")
+	sl.With("uid", 42).WithGroup("req").With("id", "abc").Warn("warning message", "status", 500, slog.Group("user", "name", "pap"))
+
+	var entry struct {
+		Message string                 `json:"msg"`
+		Level   string                 `json:"level"`
+		Data    map[string]interface{} `json:"data"`
+	}
+
+	if err := json.Unmarshal(output.Bytes(), &entry); err != nil {
+		t.Fatalf("unexpected output %q: %v", output.String(), err)
+	}
+
+	if entry.Message != "warning message" || entry.Level != "warning" {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+
+	expected := map[string]interface{}{
+		"ip":  "127.0.0.1",
+		"uid": float64(42),
+		"req": map[string]interface{}{
+			"id":     "abc",
+			"status": float64(500),
+			"user": map[string]interface{}{
+				"name": "pap",
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(entry.Data, expected) {
+		t.Errorf("unexpected data %v", entry.Data)
+	}
+}
+
+func TestSlogHandlerSharedGroup(t *testing.T) {
+	var output bytes.Buffer
+
+	lg := New(
+		EnableOutputConsole(true),
+		EnableOutputConsoleInJSONFormat(true),
+		ConsoleOutput(&output),
+		LogLevels(LogLevelInfo),
+	)
+
+	req := map[string]interface{}{"id": "r1"}
+	child := lg.WithFields(map[string]interface{}{"req": req})
+	sl := slog.New(child.SlogHandler())
+	sl.WithGroup("req").Info("one", "status", 200)
+	sl.WithGroup("req").With("attempt", 1).Info("two")
+	child.Info("plain")
+	lg.Shutdown()
+
+	if !reflect.DeepEqual(req, map[string]interface{}{"id": "r1"}) {
+		t.Errorf("caller map is modified: %v", req)
+	}
+
+	entries := decodeEntries(t, &output)
+	if len(entries) != 3 {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+
+	expected := []map[string]interface{}{
+		{"id": "r1", "status": float64(200)},
+		{"id": "r1", "attempt": float64(1)},
+		{"id": "r1"},
+	}
+	for i, e := range expected {
+		if !reflect.DeepEqual(entries[i].Data["req"], e) {
+			t.Errorf("unexpected group of entry %q: %v", entries[i].Message, entries[i].Data["req"])
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package loge
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strconv"
+)
+
+// Stack trace capture modes
+const (
+	StackTraceGoroutine  uint32 = 1 // capture the stack of the goroutine making the log call
+	StackTraceErrorChain uint32 = 2 // unwrap the errors.Unwrap chain of the error attached with WithError
+)
+
+const (
+	maxStackDepth      = 64
+	maxErrorChainDepth = 32
+)
+
+// StackFrame is a single frame of the captured stack trace
+type StackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// StackTrace returns a function to capture stack traces for entries at or above the level.
+// Mode is a combination of StackTraceGoroutine and StackTraceErrorChain.
+func StackTrace(level uint32, mode uint32) func(*configuration) *configuration {
+	return func(l *configuration) *configuration {
+		l.StackTraceLevel = level
+		l.StackTraceMode = mode
+		return l
+	}
+}
+
+// WithError creates a new log entry with the error attached
+func WithError(err error) *BufferElement {
+	return std.WithError(err)
+}
+
+// WithError creates a new log entry with the error attached
+func (lg *Logger) WithError(err error) *BufferElement {
+	return lg.newElement().WithError(err)
+}
+
+// WithError attaches the error to the log entry, the error message is stored in the "error" field
+func (be *BufferElement) WithError(err error) *BufferElement {
+	if err != nil {
+		be.err = err
+		be.With("error", safeString(err))
+	}
+	return be
+}
+
+func (l *logger) stackTraceEnabled(level uint32) bool {
+	return l.configuration.StackTraceMode != 0 && l.configuration.StackTraceLevel != 0 &&
+		levelSeverity(level) >= levelSeverity(l.configuration.StackTraceLevel)
+}
+
+func (l *logger) fillStackTrace(be *BufferElement, pcs []uintptr) {
+	if (l.configuration.StackTraceMode & StackTraceGoroutine) != 0 {
+		frames := runtime.CallersFrames(pcs)
+		for {
+			frame, more := frames.Next()
+			if frame.Function != "" && frame.Function != "runtime.goexit" {
+				be.Stack = append(be.Stack, StackFrame{
+					Function: frame.Function,
+					File:     frame.File,
+					Line:     frame.Line,
+				})
+			}
+
+			if !more {
+				break
+			}
+		}
+	}
+
+	if (l.configuration.StackTraceMode&StackTraceErrorChain) != 0 && be.err != nil {
+		be.ErrorChain = appendErrorChain(be.ErrorChain, be.err, 0)
+	}
+}
+
+func appendErrorChain(chain []string, err error, depth int) []string {
+	for err != nil && len(chain) < maxErrorChainDepth && depth < maxErrorChainDepth {
+		chain = append(chain, fmt.Sprintf("%T: %s", err, err.Error()))
+
+		if multi, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range multi.Unwrap() {
+				chain = appendErrorChain(chain, e, depth+1)
+			}
+			return chain
+		}
+
+		err = errors.Unwrap(err)
+		depth++
+	}
+
+	return chain
+}
+
+// appendStackText renders the error chain and the stack trace on indented continuation lines
+func appendStackText(buf []byte, be *BufferElement, escaping uint32) []byte {
+	return appendStackFrames(appendErrorChainText(buf, be.ErrorChain, escaping), be.Stack)
+}
+
+func appendErrorChainText(buf []byte, chain []string, escaping uint32) []byte {
+	for i, e := range chain {
+		if i == 0 {
+			buf = append(buf, "\terror: "...)
+		} else {
+			buf = append(buf, "\tcaused by: "...)
+		}
+		buf = appendEscaped(buf, e, escaping)
+		buf = append(buf, '\n')
+	}
+
+	return buf
+}
+
+func appendStackFrames(buf []byte, stack []StackFrame) []byte {
+	for _, f := range stack {
+		buf = append(buf, '\t')
+		buf = append(buf, f.Function...)
+		buf = append(buf, "\n\t\t"...)
+		buf = append(buf, f.File...)
+		buf = append(buf, ':')
+		buf = strconv.AppendInt(buf, int64(f.Line), 10)
+		buf = append(buf, '\n')
+	}
+
+	return buf
+}
@@ -0,0 +1,76 @@
+package loge
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestStackTrace(t *testing.T) {
+	var output bytes.Buffer
+
+	lg := New(
+		EnableOutputConsole(true),
+		EnableOutputConsoleInJSONFormat(true),
+		ConsoleOutput(&output),
+		LogLevels(LogLevelInfo|LogLevelError),
+		StackTrace(LogLevelWarning, StackTraceGoroutine|StackTraceErrorChain),
+	)
+	defer lg.Shutdown()
+
+	cause := errors.New("connection refused")
+	lg.WithError(fmt.Errorf("query failed: %w", cause)).Info("no stack")
+	lg.WithError(fmt.Errorf("query failed: %w", cause)).Error("with stack")
+
+	dec := json.NewDecoder(&output)
+	for dec.More() {
+		var entry struct {
+			Message    string                 `json:"msg"`
+			Stack      []StackFrame           `json:"stack"`
+			ErrorChain []string               `json:"error_chain"`
+			Data       map[string]interface{} `json:"data"`
+		}
+		if err := dec.Decode(&entry); err != nil {
+			t.Fatal(err)
+		}
+
+		if entry.Data["error"] != "query failed: connection refused" {
+			t.Errorf("unexpected data %v", entry.Data)
+		}
+
+		switch entry.Message {
+		case "no stack":
+			if entry.Stack != nil || entry.ErrorChain != nil {
+				t.Errorf("unexpected stack trace %v %v", entry.Stack, entry.ErrorChain)
+			}
+		case "with stack":
+			if len(entry.Stack) == 0 || !strings.HasSuffix(entry.Stack[0].Function, ".TestStackTrace") {
+				t.Errorf("unexpected stack trace %v", entry.Stack)
+			}
+
+			expected := []string{"*fmt.wrapError: query failed: connection refused", "*errors.errorString: connection refused"}
+			if strings.Join(entry.ErrorChain, "|") != strings.Join(expected, "|") {
+				t.Errorf("unexpected error chain %v", entry.ErrorChain)
+			}
+		}
+	}
+}
+
+func TestStackTraceText(t *testing.T) {
+	be := testElement(LogLevelError, "failed", nil)
+	be.ErrorChain = []string{"*errors.errorString: failed"}
+	be.Stack = []StackFrame{{Function: "main.main", File: "/src/main.go", Line: 12}}
+
+	out, err := TextEncoder{}.Encode(nil, be)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "2020/05/17 10:20:30.123456 failed\n\terror: *errors.errorString: failed\n\tmain.main\n\t\t/src/main.go:12\n"
+	if string(out) != expected {
+		t.Errorf("unexpected output %q", out)
+	}
+}
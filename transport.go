@@ -1,6 +1,7 @@
 package loge
 
 import (
+	"io"
 	"sync"
 )
 
@@ -20,6 +21,7 @@ type WrappedTransport struct {
 	transLocker sync.Mutex
 	wg          sync.WaitGroup
 	terminated  bool
+	flushLock   sync.Mutex
 
 	handler TransactionHandler
 }
@@ -34,21 +36,21 @@ func WrapTransport(buffer TransactionList, handler TransactionHandler) *WrappedT
 		trans:   make([]uint64, 0),
 	}
 
+	ft.wg.Add(1)
 	go ft.loop()
 	return ft
 }
 
 func (ft *WrappedTransport) loop() {
-	ft.wg.Add(1)
 	defer ft.wg.Done()
 
 	for {
 		select {
 		case <-ft.done:
-			ft.flushAll()
+			ft.Flush()
 			return
 		case <-ft.signal:
-			ft.flushAll()
+			ft.Flush()
 		}
 	}
 }
@@ -65,6 +67,13 @@ func (ft *WrappedTransport) NewTransaction(id uint64) {
 	}
 }
 
+// Flush synchronously writes out all the pending transactions /Flusher handler
+func (ft *WrappedTransport) Flush() {
+	ft.flushLock.Lock()
+	ft.flushAll()
+	ft.flushLock.Unlock()
+}
+
 // Stop /Transport handler
 func (ft *WrappedTransport) Stop() {
 	close(ft.done)
@@ -88,10 +97,35 @@ func (ft *WrappedTransport) flushAll() {
 
 	for _, id := range ids {
 		tr, ok := ft.buffer.Get(id, true)
-		if ok {
+		if ok && len(tr.Items) > 0 {
 			ft.handler.WriteOutTransaction(tr)
 		}
 	}
 
 	ft.handler.FlushTransactions()
 }
+
+type writerHandler struct {
+	w   io.Writer
+	enc Encoder
+	buf []byte
+}
+
+// NewWriterTransport creates a transport writing the entries serialized with the encoder into the writer.
+// If the writer implements Flush() error it is flushed after each batch of transactions.
+func NewWriterTransport(buffer TransactionList, w io.Writer, enc Encoder) *WrappedTransport {
+	return WrapTransport(buffer, &writerHandler{w: w, enc: enc})
+}
+
+func (h *writerHandler) WriteOutTransaction(tr *Transaction) {
+	for _, be := range tr.Items {
+		h.buf = encodeEntry(h.enc, h.buf[:0], be)
+		h.w.Write(h.buf)
+	}
+}
+
+func (h *writerHandler) FlushTransactions() {
+	if f, ok := h.w.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+}